@@ -9,6 +9,8 @@ import (
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/config"
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
 	internalhttp "github.com/bielrodrigues/task-manager-pro-backend/internal/http"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/notifications"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/storage"
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/tasks"
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/users"
 )
@@ -23,9 +25,27 @@ func main() {
 	redisClient := cache.NewClientRedis(config.RedisURL)
 	tasks.SetRedisClient(redisClient)
 
+	// Storage dos anexos (disco local)
+	fileStorage, err := storage.NewLocalStorage(config.AttachmentsDir)
+	if err != nil {
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+	tasks.SetStorage(fileStorage)
+	tasks.SetAttachmentLimits(config.AttachmentMaxBytes, config.AttachmentQuotaBytes)
+	tasks.SetFuzzyThreshold(config.SearchFuzzyThreshold)
+	tasks.SetSearchCacheTTL(config.SearchCacheTTL)
+
 	// Migrations
 	users.Migrate()
 	tasks.Migrate()
+	notifications.Migrate()
+
+	// Jobs em background
+	tasks.StartRecurrenceGenerator(config.RecurrenceInterval)
+	tasks.StartTrashPurger(config.TrashRetention)
+	tasks.StartRankRebalancer(config.RankRebalanceInterval)
+	tasks.StartReminderScheduler(config.ReminderInterval)
+	notifications.StartDispatcher(notifications.LogChannel{}, config.NotificationInterval)
 
 	// Cria router Gin
 	r := gin.Default()
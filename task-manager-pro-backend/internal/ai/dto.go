@@ -1,6 +1,10 @@
 package ai
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/tasks"
+)
 
 type SuggestTitleRequest struct {
 	Description string `json:"description"`
@@ -49,3 +53,8 @@ func (r SuggestTitleRequest) Validate() string {
 	}
 	return ""
 }
+
+type ChecklistFromAIResponse struct {
+	ImprovedDescription string                `json:"improved_description"`
+	Checklist           []tasks.ChecklistItem `json:"checklist"`
+}
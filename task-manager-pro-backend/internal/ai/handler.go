@@ -3,9 +3,11 @@ package ai
 import (
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/auth"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/tasks"
 	"github.com/gin-gonic/gin"
 )
 
@@ -94,3 +96,59 @@ func (h *Handler) ImproveDescription(c *gin.Context) {
 		Bullets:             bullets,
 	})
 }
+
+// ChecklistFromDescription gera os bullets do ImproveDescription para uma task
+// existente e salva cada bullet como item de checklist da task.
+func (h *Handler) ChecklistFromDescription(c *gin.Context) {
+	start := time.Now()
+	userID, _ := auth.GetUserID(c)
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	taskID := uint(id64)
+
+	task, err := tasks.GetTaskByID(userID, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	req := ImproveDescriptionRequest{Title: task.Title, Description: task.Description}
+	if msg := req.Validate(); msg != "" {
+		log.Printf("[AI] user=%d endpoint=/ai/tasks/:id/checklist status=400 ms=%d err=%s",
+			userID, time.Since(start).Milliseconds(), msg,
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	improved, bullets, err := h.service.ImproveDescription(c.Request.Context(), req.Title, req.Description)
+	if err != nil {
+		log.Printf("[AI] user=%d endpoint=/ai/tasks/:id/checklist status=500 ms=%d err=%v",
+			userID, time.Since(start).Milliseconds(), err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to improve description"})
+		return
+	}
+
+	items, err := tasks.AddChecklistItems(userID, taskID, bullets)
+	if err != nil {
+		log.Printf("[AI] user=%d endpoint=/ai/tasks/:id/checklist status=500 ms=%d err=%v",
+			userID, time.Since(start).Milliseconds(), err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create checklist items"})
+		return
+	}
+
+	log.Printf("[AI] user=%d endpoint=/ai/tasks/:id/checklist status=201 ms=%d",
+		userID, time.Since(start).Milliseconds(),
+	)
+
+	c.JSON(http.StatusCreated, ChecklistFromAIResponse{
+		ImprovedDescription: improved,
+		Checklist:           items,
+	})
+}
@@ -34,5 +34,5 @@ func (r *RedisClient) LPush(key string, value string) error {
 }
 
 func (r *RedisClient) LTrim(key string, start, stop int64) error {
-	return r.Client.LPush(ctx, key, start, stop).Err()
+	return r.Client.LTrim(ctx, key, start, stop).Err()
 }
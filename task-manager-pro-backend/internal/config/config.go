@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +13,32 @@ var (
 	DatabaseUrl string
 	RedisURL    string
 	JWTSecret   string
+
+	// Intervalo do gerador de tasks recorrentes (RECURRENCE_INTERVAL, ex: "1m")
+	RecurrenceInterval time.Duration
+
+	// Tempo que uma task fica na lixeira antes do purge automático
+	// (TRASH_RETENTION, ex: "720h")
+	TrashRetention time.Duration
+
+	// Intervalo do rebalanceamento dos ranks do board (RANK_REBALANCE_INTERVAL)
+	RankRebalanceInterval time.Duration
+
+	// Intervalos do scheduler de lembretes e do envio do outbox de
+	// notificações (REMINDER_INTERVAL, NOTIFICATION_INTERVAL)
+	ReminderInterval     time.Duration
+	NotificationInterval time.Duration
+
+	// Anexos: diretório local, tamanho máximo por arquivo e cota por usuário
+	// (ATTACHMENTS_DIR, ATTACHMENT_MAX_BYTES, ATTACHMENT_QUOTA_BYTES)
+	AttachmentsDir       string
+	AttachmentMaxBytes   int64
+	AttachmentQuotaBytes int64
+
+	// Similaridade mínima (0..1) da busca fuzzy (SEARCH_FUZZY_THRESHOLD) e
+	// tempo de vida dos resultados da busca no cache (SEARCH_CACHE_TTL)
+	SearchFuzzyThreshold float64
+	SearchCacheTTL       time.Duration
 )
 
 func Load() {
@@ -23,4 +51,68 @@ func Load() {
 	if DatabaseUrl == "" || RedisURL == "" || JWTSecret == "" {
 		log.Fatal("Missing environment variables")
 	}
+
+	RecurrenceInterval = durationEnv("RECURRENCE_INTERVAL", time.Minute)
+	TrashRetention = durationEnv("TRASH_RETENTION", 30*24*time.Hour)
+	RankRebalanceInterval = durationEnv("RANK_REBALANCE_INTERVAL", 10*time.Minute)
+	ReminderInterval = durationEnv("REMINDER_INTERVAL", 30*time.Second)
+	NotificationInterval = durationEnv("NOTIFICATION_INTERVAL", 30*time.Second)
+
+	AttachmentsDir = os.Getenv("ATTACHMENTS_DIR")
+	if AttachmentsDir == "" {
+		AttachmentsDir = "uploads"
+	}
+	AttachmentMaxBytes = int64Env("ATTACHMENT_MAX_BYTES", 10<<20)
+	AttachmentQuotaBytes = int64Env("ATTACHMENT_QUOTA_BYTES", 100<<20)
+
+	SearchFuzzyThreshold = floatEnv("SEARCH_FUZZY_THRESHOLD", 0.3)
+	SearchCacheTTL = durationEnv("SEARCH_CACHE_TTL", 30*time.Second)
+}
+
+// durationEnv lê uma duração opcional do ambiente, com valor padrão.
+func durationEnv(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, raw, def)
+		return def
+	}
+
+	return d
+}
+
+// int64Env lê um inteiro positivo opcional do ambiente, com valor padrão.
+func int64Env(key string, def int64) int64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s=%q, using default %d", key, raw, def)
+		return def
+	}
+
+	return n
+}
+
+// floatEnv lê uma fração opcional (0 < x <= 1) do ambiente, com valor padrão.
+func floatEnv(key string, def float64) float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || f <= 0 || f > 1 {
+		log.Printf("Invalid %s=%q, using default %g", key, raw, def)
+		return def
+	}
+
+	return f
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/ai"
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/auth"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/notifications"
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/tasks"
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/users"
 )
@@ -40,8 +41,13 @@ func RegisterRoutes(r *gin.Engine) {
 	aiGroup.POST("/suggest-title", aiHandler.SuggestTitles)
 	aiGroup.POST("/improve-description", aiHandler.ImproveDescription)
 
+	// bullets do improve-description -> checklist da task
+	aiGroup.POST("/tasks/:id/checklist", tasks.InvalidateSearchCacheOnWrite(), aiHandler.ChecklistFromDescription)
+
 	// ===== TASKS =====
 	tasksGroup := protected.Group("/tasks")
+	// escritas invalidam o cache de busca do usuário
+	tasksGroup.Use(tasks.InvalidateSearchCacheOnWrite())
 
 	// LIST (sem cache, com filtros)
 	tasksGroup.GET("", tasks.ListarTaskHandler)
@@ -50,7 +56,31 @@ func RegisterRoutes(r *gin.Engine) {
 	tasksGroup.GET("/search", tasks.SearchTasksHandler)
 
 	// HISTORY -> /api/tasks/search/history
-	tasksGroup.GET("/search/history", tasks.GetSearchHistoryHandler)
+	// grupo próprio: mexer no histórico não muda nenhuma task, então não
+	// invalida o cache de busca
+	historyGroup := protected.Group("/tasks/search/history")
+	historyGroup.GET("", tasks.GetSearchHistoryHandler)
+	historyGroup.DELETE("", tasks.ClearSearchHistoryHandler)
+	historyGroup.DELETE("/entry", tasks.DeleteSearchHistoryEntryHandler)
+
+	// buscas fixadas (não saem no corte das últimas 10)
+	historyGroup.POST("/pins", tasks.PinSearchHandler)
+	historyGroup.DELETE("/pins", tasks.UnpinSearchHandler)
+
+	// opt-out da gravação do histórico
+	historyGroup.GET("/settings", tasks.GetSearchHistorySettingsHandler)
+	historyGroup.PUT("/settings", tasks.UpdateSearchHistorySettingsHandler)
+
+	// SUGGEST (autocomplete: histórico, tags e títulos) -> /api/tasks/search/suggest
+	tasksGroup.GET("/search/suggest", tasks.SuggestSearchHandler)
+
+	// ESTIMATES (estimado x realizado, mesmos filtros do LIST) -> /api/tasks/estimates
+	tasksGroup.GET("/estimates", tasks.EstimateSummaryHandler)
+
+	// TRASH -> /api/tasks/trash
+	tasksGroup.GET("/trash", tasks.ListTrashHandler)
+	tasksGroup.DELETE("/trash", tasks.EmptyTrashHandler)
+	tasksGroup.DELETE("/trash/:id", tasks.PurgeTaskHandler)
 
 	// GET por ID -> /api/tasks/:id
 	tasksGroup.GET("/:id", tasks.GetTaskHandler)
@@ -58,9 +88,117 @@ func RegisterRoutes(r *gin.Engine) {
 	// CREATE -> /api/tasks
 	tasksGroup.POST("", tasks.CreateTaskHandler)
 
+	// BULK -> /api/tasks/bulk
+	tasksGroup.POST("/bulk", tasks.BulkTasksHandler)
+
 	// UPDATE -> /api/tasks/:id
 	tasksGroup.PUT("/:id", tasks.UpdateTaskHandler)
 
-	// DELETE -> /api/tasks/:id
+	// DELETE (lixeira) -> /api/tasks/:id
 	tasksGroup.DELETE("/:id", tasks.DeleteTaskHandler)
+
+	// RESTORE -> /api/tasks/:id/restore
+	tasksGroup.POST("/:id/restore", tasks.RestoreTaskHandler)
+
+	// MOVE (board) -> /api/tasks/:id/move
+	tasksGroup.POST("/:id/move", tasks.MoveTaskHandler)
+
+	// REMINDERS -> /api/tasks/:id/reminders
+	tasksGroup.GET("/:id/reminders", tasks.ListRemindersHandler)
+	tasksGroup.POST("/:id/reminders", tasks.CreateReminderHandler)
+	tasksGroup.DELETE("/:id/reminders/:reminderId", tasks.DeleteReminderHandler)
+
+	// TIME TRACKING -> /api/tasks/:id/timer, /api/tasks/:id/time-entries
+	tasksGroup.POST("/:id/timer/start", tasks.StartTimerHandler)
+	tasksGroup.GET("/:id/time-entries", tasks.ListTimeEntriesHandler)
+	tasksGroup.POST("/:id/time-entries", tasks.AddTimeEntryHandler)
+	tasksGroup.DELETE("/:id/time-entries/:entryId", tasks.DeleteTimeEntryHandler)
+
+	// SUBTASKS -> /api/tasks/:id/subtasks
+	tasksGroup.GET("/:id/subtasks", tasks.ListSubtasksHandler)
+	tasksGroup.POST("/:id/subtasks", tasks.CreateSubtaskHandler)
+
+	// CHECKLIST -> /api/tasks/:id/checklist
+	tasksGroup.GET("/:id/checklist", tasks.ListChecklistHandler)
+	tasksGroup.POST("/:id/checklist", tasks.CreateChecklistItemHandler)
+	tasksGroup.POST("/:id/checklist/bulk", tasks.BulkCreateChecklistHandler)
+	tasksGroup.PUT("/:id/checklist/:itemId", tasks.UpdateChecklistItemHandler)
+	tasksGroup.DELETE("/:id/checklist/:itemId", tasks.DeleteChecklistItemHandler)
+
+	// DEPENDENCIES -> /api/tasks/:id/dependencies
+	tasksGroup.GET("/:id/dependencies", tasks.ListDependenciesHandler)
+	tasksGroup.POST("/:id/dependencies", tasks.AddDependencyHandler)
+	tasksGroup.DELETE("/:id/dependencies/:blockedById", tasks.RemoveDependencyHandler)
+
+	// STATUS HISTORY -> /api/tasks/:id/transitions
+	tasksGroup.GET("/:id/transitions", tasks.GetStatusHistoryHandler)
+
+	// ACTIVITY -> /api/tasks/:id/activity
+	tasksGroup.GET("/:id/activity", tasks.ListActivityHandler)
+
+	// COMMENTS -> /api/tasks/:id/comments
+	tasksGroup.GET("/:id/comments", tasks.ListCommentsHandler)
+	tasksGroup.POST("/:id/comments", tasks.CreateCommentHandler)
+	tasksGroup.PUT("/:id/comments/:commentId", tasks.UpdateCommentHandler)
+	tasksGroup.DELETE("/:id/comments/:commentId", tasks.DeleteCommentHandler)
+
+	// ATTACHMENTS -> /api/tasks/:id/attachments
+	tasksGroup.GET("/:id/attachments", tasks.ListAttachmentsHandler)
+	tasksGroup.POST("/:id/attachments", tasks.UploadAttachmentHandler)
+	tasksGroup.GET("/:id/attachments/:attachmentId", tasks.DownloadAttachmentHandler)
+	tasksGroup.DELETE("/:id/attachments/:attachmentId", tasks.DeleteAttachmentHandler)
+
+	// ===== TAGS =====
+	tagsGroup := protected.Group("/tags")
+	tagsGroup.Use(tasks.InvalidateSearchCacheOnWrite())
+	tagsGroup.GET("", tasks.ListTagsHandler)
+	tagsGroup.PUT("/:id", tasks.UpdateTagHandler)
+	tagsGroup.POST("/:id/merge", tasks.MergeTagHandler)
+	tagsGroup.DELETE("/:id", tasks.DeleteTagHandler)
+
+	// ===== CUSTOM FIELDS =====
+	customFieldsGroup := protected.Group("/custom-fields")
+	customFieldsGroup.Use(tasks.InvalidateSearchCacheOnWrite())
+	customFieldsGroup.GET("", tasks.ListCustomFieldsHandler)
+	customFieldsGroup.POST("", tasks.CreateCustomFieldHandler)
+	customFieldsGroup.PUT("/:id", tasks.UpdateCustomFieldHandler)
+	customFieldsGroup.DELETE("/:id", tasks.DeleteCustomFieldHandler)
+
+	// ===== TIME TRACKING =====
+	timeGroup := protected.Group("/time")
+	timeGroup.GET("/timer", tasks.RunningTimerHandler)
+	timeGroup.POST("/timer/stop", tasks.StopTimerHandler)
+	timeGroup.GET("/totals", tasks.TimeTotalsHandler)
+	timeGroup.GET("/report", tasks.TimeReportHandler)
+
+	// ===== NOTIFICATIONS =====
+	notificationsGroup := protected.Group("/notifications")
+	notificationsGroup.GET("", notifications.ListHandler)
+	notificationsGroup.POST("/:id/read", notifications.MarkReadHandler)
+
+	// ===== PROJECTS =====
+	projectsGroup := protected.Group("/projects")
+	projectsGroup.Use(tasks.InvalidateSearchCacheOnWrite())
+	projectsGroup.GET("", tasks.ListProjectsHandler)
+	projectsGroup.POST("", tasks.CreateProjectHandler)
+	projectsGroup.GET("/:id", tasks.GetProjectHandler)
+	projectsGroup.PUT("/:id", tasks.UpdateProjectHandler)
+	projectsGroup.DELETE("/:id", tasks.DeleteProjectHandler)
+
+	// ===== SAVED VIEWS =====
+	viewsGroup := protected.Group("/views")
+	viewsGroup.GET("", tasks.ListViewsHandler)
+	viewsGroup.POST("", tasks.CreateViewHandler)
+	viewsGroup.GET("/:id", tasks.GetViewHandler)
+	viewsGroup.PUT("/:id", tasks.UpdateViewHandler)
+	viewsGroup.DELETE("/:id", tasks.DeleteViewHandler)
+	viewsGroup.GET("/:id/tasks", tasks.ListViewTasksHandler)
+
+	// ===== WORKFLOW =====
+	workflowGroup := protected.Group("/workflow")
+	workflowGroup.GET("", tasks.GetWorkflowHandler)
+	workflowGroup.POST("/states", tasks.CreateWorkflowStateHandler)
+	workflowGroup.DELETE("/states/:name", tasks.DeleteWorkflowStateHandler)
+	workflowGroup.POST("/transitions", tasks.CreateWorkflowTransitionHandler)
+	workflowGroup.DELETE("/transitions", tasks.DeleteWorkflowTransitionHandler)
 }
@@ -0,0 +1,16 @@
+package notifications
+
+import (
+	"log"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+)
+
+func Migrate() {
+	err := database.DB.AutoMigrate(&Notification{})
+	if err != nil {
+		log.Fatal("Failed to migrate notifications table:", err)
+	}
+
+	log.Println("Notifications table migrated")
+}
@@ -0,0 +1,22 @@
+package notifications
+
+import "time"
+
+const KindReminder = "reminder"
+
+// Notification é uma linha do outbox: quem gera grava na mesma transação da
+// mudança que a originou e os canais de entrega consomem as pendentes.
+type Notification struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"index"`
+	TaskID      *uint      `json:"task_id,omitempty" gorm:"index"`
+	ReminderID  *uint      `json:"reminder_id,omitempty" gorm:"uniqueIndex"` // um lembrete gera no máximo uma notificação
+	Kind        string     `json:"kind"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body" gorm:"type:text"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty" gorm:"index"`
+	Attempts    int        `json:"-"`
+	LastError   string     `json:"-"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
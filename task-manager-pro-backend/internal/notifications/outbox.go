@@ -0,0 +1,95 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxAttempts é quantas vezes um canal tenta entregar antes de desistir.
+const maxAttempts = 5
+
+// Channel é um meio de entrega (log, e-mail, push, webhook...).
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, n Notification) error
+}
+
+// Enqueue grava a notificação no outbox usando a transação de quem a gerou.
+func Enqueue(tx *gorm.DB, n *Notification) error {
+	return tx.Create(n).Error
+}
+
+// DeliverPending entrega ao canal as notificações pendentes. As linhas ficam
+// travadas (SKIP LOCKED) durante a entrega, então mais de uma instância pode
+// rodar o dispatcher sem entregar a mesma notificação duas vezes.
+func DeliverPending(ctx context.Context, ch Channel, limit int) (int, error) {
+	delivered := 0
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var pending []Notification
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND attempts < ?", maxAttempts).
+			Order("id ASC").
+			Limit(limit).
+			Find(&pending).Error; err != nil {
+			return err
+		}
+
+		for i := range pending {
+			n := &pending[i]
+			if err := ch.Deliver(ctx, *n); err != nil {
+				log.Printf("[notifications] channel=%s id=%d delivery failed: %v", ch.Name(), n.ID, err)
+				if err := tx.Model(n).Updates(map[string]any{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": err.Error(),
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Model(n).Update("delivered_at", time.Now()).Error; err != nil {
+				return err
+			}
+			delivered++
+		}
+
+		return nil
+	})
+
+	return delivered, err
+}
+
+func StartDispatcher(ch Channel, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			n, err := DeliverPending(context.Background(), ch, 100)
+			if err != nil {
+				log.Printf("[notifications] channel=%s dispatch failed: %v", ch.Name(), err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("[notifications] channel=%s delivered %d notification(s)", ch.Name(), n)
+			}
+		}
+	}()
+}
+
+// LogChannel só escreve a notificação no log; serve de canal padrão
+// enquanto não há e-mail/push configurado.
+type LogChannel struct{}
+
+func (LogChannel) Name() string { return "log" }
+
+func (LogChannel) Deliver(_ context.Context, n Notification) error {
+	log.Printf("[notifications] user=%d %s: %s", n.UserID, n.Title, n.Body)
+	return nil
+}
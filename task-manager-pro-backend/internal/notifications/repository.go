@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+// List devolve as notificações do usuário, das mais novas para as mais antigas.
+func List(userID uint, unreadOnly bool, limit int) ([]Notification, error) {
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	db := database.DB.Where("user_id = ?", userID)
+	if unreadOnly {
+		db = db.Where("read_at IS NULL")
+	}
+
+	items := []Notification{}
+	if err := db.Order("created_at DESC, id DESC").Limit(limit).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func MarkRead(userID uint, id uint) error {
+	res := database.DB.Model(&Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		// já lida ou inexistente: só é erro se não existir
+		var count int64
+		if err := database.DB.Model(&Notification{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+
+	return nil
+}
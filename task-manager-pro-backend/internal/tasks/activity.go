@@ -0,0 +1,160 @@
+package tasks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+// Campos rastreados no histórico, na ordem em que aparecem
+var activityFields = []string{"title", "description", "priority", "status", "due_date", "tags", "recurrence", "project_id",
+	"estimate_minutes", "story_points", "remaining_minutes"}
+
+func snapshotTask(t *Task) map[string]string {
+	due := ""
+	if t.DueDate != nil {
+		due = t.DueDate.UTC().Format(time.RFC3339)
+	}
+
+	names := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		names = append(names, tag.Name)
+	}
+	sort.Strings(names)
+
+	project := ""
+	if t.ProjectID != nil {
+		project = strconv.FormatUint(uint64(*t.ProjectID), 10)
+	}
+
+	return map[string]string{
+		"title":       t.Title,
+		"description": t.Description,
+		"priority":    t.Priority,
+		"status":      t.Status,
+		"due_date":    due,
+		"tags":        strings.Join(names, ", "),
+		"recurrence":  t.Recurrence,
+		"project_id":  project,
+
+		"estimate_minutes":  optionalNumber(t.EstimateMinutes),
+		"story_points":      optionalNumber(t.StoryPoints),
+		"remaining_minutes": optionalNumber(t.RemainingMinutes),
+	}
+}
+
+func optionalNumber[T int | float64](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(*v)
+}
+
+func recordCreated(tx *gorm.DB, actorID uint, task *Task) error {
+	snap := snapshotTask(task)
+
+	entries := []TaskActivity{}
+	for _, field := range activityFields {
+		if snap[field] == "" {
+			continue
+		}
+		entries = append(entries, TaskActivity{
+			TaskID:   task.ID,
+			UserID:   task.UserID,
+			ActorID:  actorID,
+			Action:   ActivityCreated,
+			Field:    field,
+			NewValue: snap[field],
+		})
+	}
+
+	if len(entries) == 0 {
+		entries = append(entries, TaskActivity{TaskID: task.ID, UserID: task.UserID, ActorID: actorID, Action: ActivityCreated})
+	}
+
+	return tx.Create(&entries).Error
+}
+
+// recordChanges grava uma linha por campo que mudou entre before e after.
+func recordChanges(tx *gorm.DB, actorID uint, task *Task, before, after map[string]string) error {
+	entries := []TaskActivity{}
+	for _, field := range activityFields {
+		if before[field] == after[field] {
+			continue
+		}
+		entries = append(entries, TaskActivity{
+			TaskID:   task.ID,
+			UserID:   task.UserID,
+			ActorID:  actorID,
+			Action:   ActivityUpdated,
+			Field:    field,
+			OldValue: before[field],
+			NewValue: after[field],
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.Create(&entries).Error
+}
+
+// recordAction grava eventos sem campo (deleted, restored, purged).
+func recordAction(tx *gorm.DB, actorID uint, task *Task, action string) error {
+	return tx.Create(&TaskActivity{
+		TaskID:   task.ID,
+		UserID:   task.UserID,
+		ActorID:  actorID,
+		Action:   action,
+		OldValue: task.Title,
+	}).Error
+}
+
+type ActivityPage struct {
+	Items    []TaskActivity `json:"items"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Total    int64          `json:"total"`
+}
+
+// ListActivity retorna o histórico (mais recente primeiro). Continua
+// disponível mesmo depois que a task é deletada.
+func ListActivity(userID uint, taskID uint, page, pageSize int) (*ActivityPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	db := database.DB.Model(&TaskActivity{}).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		// task sem histórico (criada antes do log) ou inexistente
+		if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	items := []TaskActivity{}
+	if err := db.
+		Order("created_at DESC, id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return &ActivityPage{Items: items, Page: page, PageSize: pageSize, Total: total}, nil
+}
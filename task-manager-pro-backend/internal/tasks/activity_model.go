@@ -0,0 +1,25 @@
+package tasks
+
+import "time"
+
+const (
+	ActivityCreated  = "created"
+	ActivityUpdated  = "updated"
+	ActivityDeleted  = "deleted"
+	ActivityRestored = "restored"
+	ActivityPurged   = "purged"
+)
+
+// TaskActivity é uma linha do histórico de alterações de uma task.
+// Em "updated" cada campo alterado gera uma linha própria.
+type TaskActivity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"task_id" gorm:"index"`
+	UserID    uint      `json:"user_id" gorm:"index"` // dono da task
+	ActorID   uint      `json:"actor_id"`             // quem fez a alteração
+	Action    string    `json:"action"`
+	Field     string    `json:"field,omitempty"`
+	OldValue  string    `json:"old_value,omitempty"`
+	NewValue  string    `json:"new_value,omitempty"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,226 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/storage"
+)
+
+var (
+	ErrStorageNotConfigured = errors.New("attachment storage is not configured")
+	ErrFileTooLarge         = errors.New("file exceeds the maximum upload size")
+	ErrQuotaExceeded        = errors.New("storage quota exceeded")
+	ErrFileTypeNotAllowed   = errors.New("file type not allowed")
+)
+
+var fileStorage storage.Storage
+
+func SetStorage(s storage.Storage) {
+	fileStorage = s
+}
+
+// Limites padrão; sobrescritos pela config em SetAttachmentLimits.
+var (
+	maxAttachmentSize int64 = 10 << 20  // 10 MB por arquivo
+	userStorageQuota  int64 = 100 << 20 // 100 MB por usuário
+)
+
+func SetAttachmentLimits(maxSize, quota int64) {
+	if maxSize > 0 {
+		maxAttachmentSize = maxSize
+	}
+	if quota > 0 {
+		userStorageQuota = quota
+	}
+}
+
+func MaxAttachmentSize() int64 {
+	return maxAttachmentSize
+}
+
+// Tipos aceitos (detectados pelo conteúdo, não pelo header do cliente)
+var allowedContentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true, // logs, .txt, .json, .csv
+	"application/zip": true,
+}
+
+func ListAttachments(userID uint, taskID uint) ([]TaskAttachment, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	items := []TaskAttachment{}
+	if err := database.DB.
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func UploadAttachment(ctx context.Context, userID uint, taskID uint, fileName string, size int64, r io.Reader) (*TaskAttachment, error) {
+	if fileStorage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	if size > maxAttachmentSize {
+		return nil, ErrFileTooLarge
+	}
+
+	var used int64
+	if err := database.DB.Model(&TaskAttachment{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&used).Error; err != nil {
+		return nil, err
+	}
+	if used+size > userStorageQuota {
+		return nil, ErrQuotaExceeded
+	}
+
+	// Detecta o tipo pelos primeiros 512 bytes
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !allowedContentTypes[mediaType] {
+		return nil, fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, mediaType)
+	}
+
+	key, err := newStorageKey(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// LimitReader com +1 byte para detectar arquivos maiores que o declarado
+	written, err := fileStorage.Save(ctx, key, io.LimitReader(br, maxAttachmentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if written > maxAttachmentSize {
+		_ = fileStorage.Delete(ctx, key)
+		return nil, ErrFileTooLarge
+	}
+
+	attachment := &TaskAttachment{
+		TaskID:      taskID,
+		UserID:      userID,
+		FileName:    sanitizeFileName(fileName),
+		ContentType: contentType,
+		Size:        written,
+		StorageKey:  key,
+	}
+	if err := database.DB.Create(attachment).Error; err != nil {
+		_ = fileStorage.Delete(ctx, key)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func findUserAttachment(userID uint, taskID uint, attachmentID uint) (*TaskAttachment, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	var attachment TaskAttachment
+	if err := database.DB.
+		Where("id = ? AND task_id = ? AND user_id = ?", attachmentID, taskID, userID).
+		First(&attachment).Error; err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// OpenAttachment devolve os metadados e o conteúdo; quem chama fecha o reader.
+func OpenAttachment(ctx context.Context, userID uint, taskID uint, attachmentID uint) (*TaskAttachment, io.ReadCloser, error) {
+	if fileStorage == nil {
+		return nil, nil, ErrStorageNotConfigured
+	}
+
+	attachment, err := findUserAttachment(userID, taskID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rc, err := fileStorage.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, rc, nil
+}
+
+func DeleteAttachment(ctx context.Context, userID uint, taskID uint, attachmentID uint) error {
+	attachment, err := findUserAttachment(userID, taskID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Delete(attachment).Error; err != nil {
+		return err
+	}
+
+	removeAttachmentFiles([]string{attachment.StorageKey})
+	return nil
+}
+
+// removeAttachmentFiles apaga os arquivos depois que as linhas já saíram do
+// banco. Falhas só são logadas: no pior caso sobra um arquivo órfão.
+func removeAttachmentFiles(keys []string) {
+	if fileStorage == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := fileStorage.Delete(context.Background(), key); err != nil {
+			log.Printf("[attachments] failed to delete %s: %v", key, err)
+		}
+	}
+}
+
+func newStorageKey(userID uint, taskID uint) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%d/%s", userID, taskID, hex.EncodeToString(b)), nil
+}
+
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
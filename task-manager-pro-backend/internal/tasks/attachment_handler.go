@@ -0,0 +1,152 @@
+package tasks
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/auth"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/storage"
+)
+
+func ListAttachmentsHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+
+	attachments, err := ListAttachments(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list attachments"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+func UploadAttachmentHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+
+	// margem de 1 MB para os cabeçalhos do multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxAttachmentSize()+(1<<20))
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": ErrFileTooLarge.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing multipart field 'file'"})
+		}
+		return
+	}
+	defer file.Close()
+
+	attachment, err := UploadAttachment(c.Request.Context(), userID, id, header.Filename, header.Size, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		case errors.Is(err, ErrFileTooLarge), errors.Is(err, ErrQuotaExceeded):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrFileTypeNotAllowed):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload attachment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func DownloadAttachmentHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "attachmentId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return
+	}
+
+	attachment, rc, err := OpenAttachment(c.Request.Context(), userID, id, attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to download attachment"})
+		}
+		return
+	}
+	defer rc.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, rc, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Content-Length":         strconv.FormatInt(attachment.Size, 10),
+	})
+}
+
+func DeleteAttachmentHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "attachmentId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return
+	}
+
+	if err := DeleteAttachment(c.Request.Context(), userID, id, attachmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete attachment"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
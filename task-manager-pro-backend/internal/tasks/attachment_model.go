@@ -0,0 +1,14 @@
+package tasks
+
+import "time"
+
+type TaskAttachment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TaskID      uint      `json:"task_id" gorm:"index"`
+	UserID      uint      `json:"user_id" gorm:"index"`
+	FileName    string    `json:"file_name" gorm:"size:255"`
+	ContentType string    `json:"content_type" gorm:"size:100"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-" gorm:"size:255;uniqueIndex"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,167 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+const MaxBulkItems = 500
+
+var (
+	ErrBulkTargetRequired = errors.New("either ids or filter is required")
+	ErrBulkTooManyItems   = fmt.Errorf("bulk operations are limited to %d tasks", MaxBulkItems)
+	ErrBulkNoChanges      = errors.New("no changes given for update")
+)
+
+// BulkTasks aplica a operação em uma única transação. Cada task roda em um
+// SAVEPOINT (transação aninhada do GORM): uma falha individual desfaz só
+// aquela task e vira um item com erro no resultado.
+func BulkTasks(userID uint, input BulkTaskInput) (*BulkResult, error) {
+	if input.Action == "update" && input.Changes.isEmpty() {
+		return nil, ErrBulkNoChanges
+	}
+
+	ids, err := bulkTargetIDs(userID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkResult{Results: make([]BulkItemResult, 0, len(ids))}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			itemErr := tx.Transaction(func(itemTx *gorm.DB) error {
+				if input.Action == "delete" {
+					return deleteTask(itemTx, userID, id)
+				}
+				return bulkUpdateTask(itemTx, userID, id, input.Changes)
+			})
+
+			item := BulkItemResult{ID: id, OK: itemErr == nil}
+			if itemErr != nil {
+				item.Error = bulkErrorMessage(itemErr)
+				result.Failed++
+			} else {
+				result.Succeeded++
+			}
+			result.Results = append(result.Results, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c BulkChangesInput) isEmpty() bool {
+	return c.Status == nil && c.Priority == nil && c.DueDate == nil &&
+		len(c.AddTags) == 0 && len(c.RemoveTags) == 0
+}
+
+// bulkTargetIDs resolve os IDs alvo: a lista explícita (deduplicada) ou as
+// tasks do usuário que batem com o filtro.
+func bulkTargetIDs(userID uint, input BulkTaskInput) ([]uint, error) {
+	if len(input.IDs) > 0 {
+		if len(input.IDs) > MaxBulkItems {
+			return nil, ErrBulkTooManyItems
+		}
+
+		seen := map[uint]bool{}
+		ids := make([]uint, 0, len(input.IDs))
+		for _, id := range input.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	if input.Filter == nil {
+		return nil, ErrBulkTargetRequired
+	}
+
+	f := input.Filter
+	filter := TaskFilter{
+		Status:       f.Status,
+		Priority:     f.Priority,
+		Tags:         f.Tags,
+		Query:        f.Query,
+		ProjectID:    f.ProjectID,
+		DueBefore:    f.DueBefore,
+		DueAfter:     f.DueAfter,
+		Overdue:      f.Overdue,
+		CreatedSince: f.CreatedSince,
+	}
+
+	var ids []uint
+	if err := taskFilterQuery(userID, filter).
+		Order("tasks.id ASC").
+		Limit(MaxBulkItems+1).
+		Pluck("tasks.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) > MaxBulkItems {
+		return nil, ErrBulkTooManyItems
+	}
+
+	return ids, nil
+}
+
+// bulkUpdateTask converte as mudanças do lote em um UpdateTaskInput, para
+// reaproveitar validação de workflow, histórico e cascatas do updateTask.
+func bulkUpdateTask(tx *gorm.DB, userID uint, id uint, changes BulkChangesInput) error {
+	input := UpdateTaskInput{
+		Status:   changes.Status,
+		Priority: changes.Priority,
+		DueDate:  changes.DueDate,
+	}
+
+	if len(changes.AddTags) > 0 || len(changes.RemoveTags) > 0 {
+		var task Task
+		if err := tx.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+			return err
+		}
+
+		remove := map[string]bool{}
+		for _, name := range changes.RemoveTags {
+			remove[normalizeTagPath(name)] = true
+		}
+
+		names := []string{}
+		seen := map[string]bool{}
+		for _, tag := range task.Tags {
+			if !remove[tag.Name] && !seen[tag.Name] {
+				seen[tag.Name] = true
+				names = append(names, tag.Name)
+			}
+		}
+		for _, name := range changes.AddTags {
+			name = normalizeTagPath(name)
+			if name != "" && !remove[name] && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		input.Tags = &names
+	}
+
+	_, err := updateTask(tx, userID, id, input)
+	return err
+}
+
+func bulkErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "task not found"
+	case errors.Is(err, ErrTaskBlocked), isValidationError(err):
+		return err.Error()
+	default:
+		return "failed to process task"
+	}
+}
@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/auth"
+)
+
+func BulkTasksHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input BulkTaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := BulkTasks(userID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBulkTargetRequired), errors.Is(err, ErrBulkTooManyItems), errors.Is(err, ErrBulkNoChanges):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply bulk operation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
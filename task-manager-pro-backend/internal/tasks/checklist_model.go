@@ -0,0 +1,19 @@
+package tasks
+
+import "time"
+
+type ChecklistItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"task_id" gorm:"index"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskProgress é o roll-up de subtasks + itens de checklist (ex: 3/5).
+type TaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
@@ -0,0 +1,155 @@
+package tasks
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var ErrEmptyComment = errors.New("comment body must not be empty")
+
+type CommentPage struct {
+	Items    []TaskComment `json:"items"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	Total    int64         `json:"total"`
+}
+
+// ListComments lista os comentários da task, do mais antigo para o mais novo.
+func ListComments(userID uint, taskID uint, page, pageSize int) (*CommentPage, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	db := database.DB.Model(&TaskComment{}).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	items := []TaskComment{}
+	if err := db.
+		Order("created_at ASC, id ASC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return &CommentPage{Items: items, Page: page, PageSize: pageSize, Total: total}, nil
+}
+
+func CreateComment(userID uint, taskID uint, body string) (*TaskComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	comment := &TaskComment{TaskID: taskID, UserID: userID, Body: body}
+	if err := database.DB.Create(comment).Error; err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+func findUserComment(userID uint, taskID uint, commentID uint) (*TaskComment, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	var comment TaskComment
+	if err := database.DB.
+		Where("id = ? AND task_id = ? AND user_id = ?", commentID, taskID, userID).
+		First(&comment).Error; err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func UpdateComment(userID uint, taskID uint, commentID uint, body string) (*TaskComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+
+	comment, err := findUserComment(userID, taskID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.Body == body {
+		return comment, nil
+	}
+
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+
+	if err := database.DB.Save(comment).Error; err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+func DeleteComment(userID uint, taskID uint, commentID uint) error {
+	comment, err := findUserComment(userID, taskID, commentID)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Delete(comment).Error
+}
+
+// attachCommentCounts preenche CommentCount das tasks em uma única query.
+func attachCommentCounts(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+
+	var rows []struct {
+		TaskID uint
+		Total  int64
+	}
+	if err := database.DB.Model(&TaskComment{}).
+		Select("task_id, COUNT(*) AS total").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, r := range rows {
+		counts[r.TaskID] = r.Total
+	}
+	for i := range tasks {
+		tasks[i].CommentCount = counts[tasks[i].ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package tasks
+
+import "time"
+
+type TaskComment struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TaskID    uint       `json:"task_id" gorm:"index"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	Body      string     `json:"body" gorm:"type:text"`
+	EditedAt  *time.Time `json:"edited_at"` // nil = nunca editado
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
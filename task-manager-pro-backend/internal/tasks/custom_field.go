@@ -0,0 +1,536 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidCustomField = errors.New("invalid custom field")
+	ErrCustomFieldExists  = errors.New("a custom field with this key already exists")
+)
+
+var fieldTypes = []string{FieldText, FieldNumber, FieldDate, FieldSelect, FieldCheckbox, FieldURL}
+
+var fieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+const maxFieldTextLength = 1000
+
+// fieldKeyFromName gera a key a partir do nome ("Ticket URL" -> "ticket_url").
+func fieldKeyFromName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+	}
+
+	key := strings.TrimRight(b.String(), "_")
+	if key != "" && key[0] >= '0' && key[0] <= '9' {
+		key = "f_" + key
+	}
+	if len(key) > 64 {
+		key = strings.TrimRight(key[:64], "_")
+	}
+	return key
+}
+
+func normalizeFieldOptions(fieldType string, options []string) ([]string, error) {
+	if fieldType != FieldSelect {
+		return nil, nil
+	}
+
+	seen := map[string]bool{}
+	out := []string{}
+	for _, o := range options {
+		o = strings.TrimSpace(o)
+		if o == "" || seen[o] {
+			continue
+		}
+		seen[o] = true
+		out = append(out, o)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: select fields need at least one option", ErrInvalidCustomField)
+	}
+	return out, nil
+}
+
+// fieldColumn é a coluna de TaskFieldValue usada por cada tipo.
+func fieldColumn(fieldType string) string {
+	switch fieldType {
+	case FieldNumber:
+		return "number_value"
+	case FieldDate:
+		return "date_value"
+	case FieldCheckbox:
+		return "bool_value"
+	default:
+		return "text_value"
+	}
+}
+
+func ListCustomFields(userID uint) ([]CustomField, error) {
+	fields := []CustomField{}
+	if err := database.DB.
+		Where("user_id = ?", userID).
+		Order("name ASC, id ASC").
+		Find(&fields).Error; err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func findCustomField(db *gorm.DB, userID uint, id uint) (*CustomField, error) {
+	var field CustomField
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&field).Error; err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// loadCustomFields devolve as definições do usuário indexadas pela key.
+func loadCustomFields(db *gorm.DB, userID uint) (map[string]*CustomField, error) {
+	var fields []CustomField
+	if err := db.Where("user_id = ?", userID).Find(&fields).Error; err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*CustomField, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+	return byKey, nil
+}
+
+func CreateCustomField(userID uint, input CreateCustomFieldInput) (*CustomField, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name must not be empty", ErrInvalidCustomField)
+	}
+
+	fieldType := strings.ToLower(strings.TrimSpace(input.Type))
+	valid := false
+	for _, t := range fieldTypes {
+		valid = valid || t == fieldType
+	}
+	if !valid {
+		return nil, fmt.Errorf("%w: type %q (allowed: %s)", ErrInvalidCustomField, input.Type, strings.Join(fieldTypes, ", "))
+	}
+
+	key := strings.TrimSpace(input.Key)
+	if key == "" {
+		key = fieldKeyFromName(name)
+	}
+	if !fieldKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: key must match %s", ErrInvalidCustomField, fieldKeyPattern)
+	}
+
+	options, err := normalizeFieldOptions(fieldType, input.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := database.DB.Model(&CustomField{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrCustomFieldExists
+	}
+
+	field := &CustomField{
+		UserID:   userID,
+		Key:      key,
+		Name:     name,
+		Type:     fieldType,
+		Options:  options,
+		Required: input.Required,
+	}
+	if err := database.DB.Create(field).Error; err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+func UpdateCustomField(userID uint, id uint, input UpdateCustomFieldInput) (*CustomField, error) {
+	field, err := findCustomField(database.DB, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name must not be empty", ErrInvalidCustomField)
+		}
+		field.Name = name
+	}
+	if input.Options != nil {
+		if field.Type != FieldSelect {
+			return nil, fmt.Errorf("%w: only select fields have options", ErrInvalidCustomField)
+		}
+		options, err := normalizeFieldOptions(field.Type, *input.Options)
+		if err != nil {
+			return nil, err
+		}
+
+		// não deixa remover uma opção que ainda está em uso
+		var inUse []string
+		if err := database.DB.Model(&TaskFieldValue{}).
+			Where("field_id = ? AND text_value NOT IN ?", field.ID, options).
+			Distinct().
+			Pluck("text_value", &inUse).Error; err != nil {
+			return nil, err
+		}
+		if len(inUse) > 0 {
+			return nil, fmt.Errorf("%w: options still in use: %s", ErrInvalidCustomField, strings.Join(inUse, ", "))
+		}
+
+		field.Options = options
+	}
+	if input.Required != nil {
+		field.Required = *input.Required
+	}
+
+	if err := database.DB.Save(field).Error; err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+// DeleteCustomField remove a definição e os valores gravados nas tasks.
+func DeleteCustomField(userID uint, id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		field, err := findCustomField(tx, userID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("field_id = ?", field.ID).Delete(&TaskFieldValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(field).Error
+	})
+}
+
+// parseFieldValue valida um valor vindo do JSON da task.
+func parseFieldValue(field *CustomField, raw any) (TaskFieldValue, error) {
+	var v TaskFieldValue
+	invalid := func(format string, args ...any) (TaskFieldValue, error) {
+		return v, fmt.Errorf("%w: %s: "+format, append([]any{ErrInvalidCustomField, field.Key}, args...)...)
+	}
+
+	switch field.Type {
+	case FieldNumber:
+		n, ok := raw.(float64)
+		if !ok {
+			return invalid("expected a number")
+		}
+		v.NumberValue = &n
+
+	case FieldCheckbox:
+		b, ok := raw.(bool)
+		if !ok {
+			return invalid("expected true or false")
+		}
+		v.BoolValue = &b
+
+	case FieldDate:
+		s, ok := raw.(string)
+		if !ok {
+			return invalid("expected a date (YYYY-MM-DD or RFC3339)")
+		}
+		t, err := parseDateParam(s)
+		if err != nil {
+			return invalid("expected a date (YYYY-MM-DD or RFC3339)")
+		}
+		v.DateValue = &t
+
+	default:
+		s, ok := raw.(string)
+		if !ok {
+			return invalid("expected a string")
+		}
+		s = strings.TrimSpace(s)
+		if len(s) > maxFieldTextLength {
+			return invalid("must be at most %d characters", maxFieldTextLength)
+		}
+
+		switch field.Type {
+		case FieldSelect:
+			found := false
+			for _, o := range field.Options {
+				found = found || o == s
+			}
+			if !found {
+				return invalid("%q is not one of: %s", s, strings.Join(field.Options, ", "))
+			}
+		case FieldURL:
+			u, err := url.Parse(s)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return invalid("expected an http(s) URL")
+			}
+		}
+		v.TextValue = &s
+	}
+
+	return v, nil
+}
+
+// parseFilterValue converte o valor de um filtro da query string.
+func parseFilterValue(field *CustomField, raw string) (any, error) {
+	switch field.Type {
+	case FieldNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cf.%s expects a number", ErrInvalidCustomField, field.Key)
+		}
+		return n, nil
+	case FieldDate:
+		t, err := parseDateParam(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cf.%s expects a date", ErrInvalidCustomField, field.Key)
+		}
+		return t, nil
+	case FieldCheckbox:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cf.%s expects true or false", ErrInvalidCustomField, field.Key)
+		}
+		return b, nil
+	default:
+		return raw, nil
+	}
+}
+
+// filterOps mapeia o operador do filtro para SQL.
+var filterOps = map[string]string{"eq": "=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+
+// resolveCustomFieldFilters valida os filtros cf.<key> contra as definições
+// do usuário e converte os valores para o tipo do campo.
+func resolveCustomFieldFilters(defs map[string]*CustomField, filter *TaskFilter) error {
+	for i := range filter.CustomFields {
+		f := &filter.CustomFields[i]
+		field, ok := defs[f.Key]
+		if !ok {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidCustomField, f.Key)
+		}
+		if f.Op == "" {
+			f.Op = "eq"
+		}
+
+		switch {
+		case f.Op == "contains":
+			if field.Type != FieldText && field.Type != FieldURL {
+				return fmt.Errorf("%w: cf.%s does not support contains", ErrInvalidCustomField, f.Key)
+			}
+		case filterOps[f.Op] == "":
+			return fmt.Errorf("%w: unknown operator %q (allowed: eq, gt, gte, lt, lte, contains)", ErrInvalidCustomField, f.Op)
+		case f.Op != "eq" && field.Type != FieldNumber && field.Type != FieldDate:
+			return fmt.Errorf("%w: cf.%s only supports eq", ErrInvalidCustomField, f.Key)
+		}
+
+		value, err := parseFilterValue(field, f.Value)
+		if err != nil {
+			return err
+		}
+		f.field = field
+		f.value = value
+	}
+	return nil
+}
+
+// customFieldCondition monta a condição SQL de um filtro já resolvido.
+func customFieldCondition(f CustomFieldFilter) (string, []any) {
+	exists := "EXISTS (SELECT 1 FROM task_field_values v WHERE v.task_id = tasks.id AND v.field_id = ? AND %s)"
+	col := "v." + fieldColumn(f.field.Type)
+
+	switch {
+	case f.field.Type == FieldCheckbox && f.value == false:
+		// checkbox sem valor conta como desmarcado
+		return "NOT EXISTS (SELECT 1 FROM task_field_values v WHERE v.task_id = tasks.id AND v.field_id = ? AND v.bool_value)",
+			[]any{f.field.ID}
+	case f.Op == "contains":
+		return fmt.Sprintf(exists, col+" ILIKE ?"), []any{f.field.ID, "%" + escapeLike(f.Value) + "%"}
+	default:
+		return fmt.Sprintf(exists, col+" "+filterOps[f.Op]+" ?"), []any{f.field.ID, f.value}
+	}
+}
+
+// fieldValueOf devolve o valor para o JSON da task.
+func fieldValueOf(fieldType string, v *TaskFieldValue) any {
+	switch fieldType {
+	case FieldNumber:
+		if v.NumberValue != nil {
+			return *v.NumberValue
+		}
+	case FieldDate:
+		if v.DateValue != nil {
+			return *v.DateValue
+		}
+	case FieldCheckbox:
+		if v.BoolValue != nil {
+			return *v.BoolValue
+		}
+	default:
+		if v.TextValue != nil {
+			return *v.TextValue
+		}
+	}
+	return nil
+}
+
+// emptyFieldValue diz se o valor recebido equivale a "sem valor": null ou
+// texto em branco.
+func emptyFieldValue(raw any) bool {
+	if raw == nil {
+		return true
+	}
+	s, ok := raw.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
+
+// setCustomFieldValues grava os valores informados na task; null (ou texto
+// em branco) remove o valor. Campos obrigatórios precisam estar presentes na
+// criação e não podem ser removidos na edição.
+func setCustomFieldValues(tx *gorm.DB, userID uint, taskID uint, values map[string]any, creating bool) error {
+	if len(values) == 0 && !creating {
+		return nil
+	}
+
+	defs, err := loadCustomFields(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	if creating {
+		for key, field := range defs {
+			if field.Required && emptyFieldValue(values[key]) {
+				return fmt.Errorf("%w: %s is required", ErrInvalidCustomField, key)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, ok := defs[key]
+		if !ok {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidCustomField, key)
+		}
+
+		if emptyFieldValue(values[key]) {
+			if field.Required {
+				return fmt.Errorf("%w: %s is required", ErrInvalidCustomField, key)
+			}
+			if err := tx.Where("task_id = ? AND field_id = ?", taskID, field.ID).
+				Delete(&TaskFieldValue{}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		v, err := parseFieldValue(field, values[key])
+		if err != nil {
+			return err
+		}
+		v.TaskID = taskID
+		v.FieldID = field.ID
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}, {Name: "field_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"text_value", "number_value", "date_value", "bool_value", "updated_at"}),
+		}).Create(&v).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyCustomFieldValues leva os valores para a próxima ocorrência de uma
+// task recorrente.
+func copyCustomFieldValues(tx *gorm.DB, from *Task, to *Task) error {
+	var values []TaskFieldValue
+	if err := tx.Where("task_id = ?", from.ID).Find(&values).Error; err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	for i := range values {
+		values[i].ID = 0
+		values[i].TaskID = to.ID
+		values[i].CreatedAt = time.Time{}
+		values[i].UpdatedAt = time.Time{}
+	}
+	return tx.Create(&values).Error
+}
+
+// attachCustomFields preenche custom_fields (key -> valor) das tasks.
+func attachCustomFields(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	index := make(map[uint]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		index[tasks[i].ID] = i
+		tasks[i].CustomFields = map[string]any{}
+	}
+
+	var rows []struct {
+		TaskID      uint
+		Key         string
+		Type        string
+		TextValue   *string
+		NumberValue *float64
+		DateValue   *time.Time
+		BoolValue   *bool
+	}
+	if err := database.DB.
+		Table("task_field_values v").
+		Select("v.task_id, f.key, f.type, v.text_value, v.number_value, v.date_value, v.bool_value").
+		Joins("JOIN custom_fields f ON f.id = v.field_id").
+		Where("v.task_id IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		value := TaskFieldValue{
+			TextValue:   r.TextValue,
+			NumberValue: r.NumberValue,
+			DateValue:   r.DateValue,
+			BoolValue:   r.BoolValue,
+		}
+		tasks[index[r.TaskID]].CustomFields[r.Key] = fieldValueOf(r.Type, &value)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package tasks
+
+import "time"
+
+// Tipos de campo customizado
+const (
+	FieldText     = "text"
+	FieldNumber   = "number"
+	FieldDate     = "date"
+	FieldSelect   = "select"
+	FieldCheckbox = "checkbox"
+	FieldURL      = "url"
+)
+
+// CustomField é a definição de um campo extra criado pelo usuário. Key é o
+// nome usado no JSON (custom_fields) e nos filtros (cf.<key>).
+type CustomField struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index:idx_user_custom_field,unique"`
+	Key       string    `json:"key" gorm:"size:64;index:idx_user_custom_field,unique"`
+	Name      string    `json:"name" gorm:"size:100"`
+	Type      string    `json:"type" gorm:"size:16"`
+	Options   []string  `json:"options,omitempty" gorm:"serializer:json"` // só para select
+	Required  bool      `json:"required" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskFieldValue guarda o valor de um campo numa task, em colunas tipadas
+// para que filtro e ordenação sejam feitos no banco.
+type TaskFieldValue struct {
+	ID          uint    `gorm:"primaryKey"`
+	TaskID      uint    `gorm:"index:idx_task_field_value,unique"`
+	FieldID     uint    `gorm:"index:idx_task_field_value,unique;index"`
+	TextValue   *string `gorm:"type:text"` // text, select, url
+	NumberValue *float64
+	DateValue   *time.Time
+	BoolValue   *bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
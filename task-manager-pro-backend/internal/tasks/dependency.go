@@ -0,0 +1,198 @@
+package tasks
+
+import (
+	"errors"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTaskBlocked        = errors.New("task is blocked by unfinished dependencies")
+	ErrSelfDependency     = errors.New("a task cannot depend on itself")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrDependencyExists   = errors.New("dependency already exists")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrBlockerNotFound    = errors.New("blocking task not found")
+)
+
+func ListDependencies(userID uint, taskID uint) (*TaskDependencies, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	deps := &TaskDependencies{BlockedBy: []Task{}, Blocking: []Task{}}
+
+	if err := database.DB.
+		Preload("Tags").
+		Joins("JOIN task_dependencies td ON td.blocked_by_id = tasks.id").
+		Where("td.task_id = ? AND tasks.user_id = ?", taskID, userID).
+		Find(&deps.BlockedBy).Error; err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.
+		Preload("Tags").
+		Joins("JOIN task_dependencies td ON td.task_id = tasks.id").
+		Where("td.blocked_by_id = ? AND tasks.user_id = ?", taskID, userID).
+		Find(&deps.Blocking).Error; err != nil {
+		return nil, err
+	}
+
+	if err := enrichTasks(deps.BlockedBy); err != nil {
+		return nil, err
+	}
+	if err := enrichTasks(deps.Blocking); err != nil {
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+func AddDependency(userID uint, taskID uint, blockedByID uint) (*TaskDependency, error) {
+	if taskID == blockedByID {
+		return nil, ErrSelfDependency
+	}
+
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+	if _, err := findUserTask(database.DB, userID, blockedByID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBlockerNotFound
+		}
+		return nil, err
+	}
+
+	dep := &TaskDependency{
+		UserID:      userID,
+		TaskID:      taskID,
+		BlockedByID: blockedByID,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Serializa as dependências do usuário: sem o lock, A->B e B->A em
+		// paralelo não enxergam o caminho uma da outra e fecham o ciclo.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('task_dependencies'), ?)", userID).Error; err != nil {
+			return err
+		}
+
+		// Se a task bloqueadora já depende (direta ou indiretamente) da
+		// task atual, a nova aresta fecharia um ciclo.
+		reachable, err := dependsOn(tx, userID, blockedByID, taskID)
+		if err != nil {
+			return err
+		}
+		if reachable {
+			return ErrDependencyCycle
+		}
+
+		// duplicadas esbarram no índice único idx_task_dependency
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(dep)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrDependencyExists
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dep, nil
+}
+
+func RemoveDependency(userID uint, taskID uint, blockedByID uint) error {
+	res := database.DB.
+		Where("user_id = ? AND task_id = ? AND blocked_by_id = ?", userID, taskID, blockedByID).
+		Delete(&TaskDependency{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDependencyNotFound
+	}
+
+	return nil
+}
+
+// dependsOn percorre o grafo de "bloqueada por" a partir de from e diz se
+// target é alcançável.
+func dependsOn(db *gorm.DB, userID uint, from uint, target uint) (bool, error) {
+	visited := map[uint]bool{from: true}
+	frontier := []uint{from}
+
+	for len(frontier) > 0 {
+		var next []uint
+		if err := db.Model(&TaskDependency{}).
+			Where("user_id = ? AND task_id IN ?", userID, frontier).
+			Pluck("blocked_by_id", &next).Error; err != nil {
+			return false, err
+		}
+
+		frontier = frontier[:0]
+		for _, id := range next {
+			if id == target {
+				return true, nil
+			}
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func isBlocked(db *gorm.DB, taskID uint) (bool, error) {
+	var count int64
+	err := db.Model(&TaskDependency{}).
+		Joins("JOIN tasks blocker ON blocker.id = task_dependencies.blocked_by_id AND blocker.deleted_at IS NULL").
+		Where("task_dependencies.task_id = ? AND blocker.status <> ?", taskID, "DONE").
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// attachBlocked preenche o campo Blocked das tasks em uma única query.
+func attachBlocked(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+
+	var blocked []uint
+	if err := database.DB.Model(&TaskDependency{}).
+		Joins("JOIN tasks blocker ON blocker.id = task_dependencies.blocked_by_id AND blocker.deleted_at IS NULL").
+		Where("task_dependencies.task_id IN ? AND blocker.status <> ?", ids, "DONE").
+		Distinct().
+		Pluck("task_dependencies.task_id", &blocked).Error; err != nil {
+		return err
+	}
+
+	set := make(map[uint]bool, len(blocked))
+	for _, id := range blocked {
+		set[id] = true
+	}
+	for i := range tasks {
+		tasks[i].Blocked = set[tasks[i].ID]
+	}
+
+	return nil
+}
+
+// deleteDependencies remove as arestas (nos dois sentidos) das tasks apagadas
+// definitivamente.
+func deleteDependencies(tx *gorm.DB, taskIDs []uint) error {
+	return tx.
+		Where("task_id IN ? OR blocked_by_id IN ?", taskIDs, taskIDs).
+		Delete(&TaskDependency{}).Error
+}
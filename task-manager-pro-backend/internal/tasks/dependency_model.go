@@ -0,0 +1,12 @@
+package tasks
+
+import "time"
+
+// TaskDependency representa "TaskID está bloqueada por BlockedByID".
+type TaskDependency struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"index"`
+	TaskID      uint      `json:"task_id" gorm:"index:idx_task_dependency,unique"`
+	BlockedByID uint      `json:"blocked_by_id" gorm:"index:idx_task_dependency,unique;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
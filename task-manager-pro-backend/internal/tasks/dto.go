@@ -11,6 +11,15 @@ type CreateTaskInput struct {
 	Status      string     `json:"status" binding:"required"`
 	DueDate     *time.Time `json:"due_date"`
 	Tags        []string   `json:"tags"`
+	Recurrence  string     `json:"recurrence"`
+	ProjectID   *uint      `json:"project_id"`
+
+	// estimativas; remaining_minutes começa igual à estimativa se omitido
+	EstimateMinutes  *int     `json:"estimate_minutes" binding:"omitempty,min=0"`
+	StoryPoints      *float64 `json:"story_points" binding:"omitempty,min=0"`
+	RemainingMinutes *int     `json:"remaining_minutes" binding:"omitempty,min=0"`
+
+	CustomFields map[string]any `json:"custom_fields"` // key -> valor
 }
 
 type UpdateTaskInput struct {
@@ -20,11 +29,225 @@ type UpdateTaskInput struct {
 	Status      *string    `json:"status"`
 	DueDate     *time.Time `json:"due_date"`
 	Tags        *[]string  `json:"tags"`
+	Recurrence  *string    `json:"recurrence"` // "" remove a recorrência
+	ProjectID   *uint      `json:"project_id"` // 0 remove do projeto
+
+	EstimateMinutes  *int     `json:"estimate_minutes" binding:"omitempty,min=0"`
+	StoryPoints      *float64 `json:"story_points" binding:"omitempty,min=0"`
+	RemainingMinutes *int     `json:"remaining_minutes" binding:"omitempty,min=0"`
+
+	CustomFields map[string]any `json:"custom_fields"` // null remove o valor
+}
+
+// MoveTaskInput posiciona a task no board: status é a coluna de destino
+// (vazio mantém a atual) e after_id/before_id são os vizinhos na coluna.
+type MoveTaskInput struct {
+	Status   string `json:"status"`
+	AfterID  *uint  `json:"after_id"`
+	BeforeID *uint  `json:"before_id"`
 }
 
 type TaskFilter struct {
-	Status   string
-	Priority string
-	Tags     string
-	Query    string
+	Status    string
+	Priority  string
+	Tags      string
+	Query     string
+	ProjectID *uint
+	Expr      string // linguagem de busca (?query=), ver query.go
+
+	DueBefore    *time.Time
+	DueAfter     *time.Time
+	Overdue      bool
+	CreatedSince *time.Time
+
+	CustomFields []CustomFieldFilter // cf.<key>[.<op>]=valor
+
+	Sort      string // created_at, updated_at, due_date, priority, title, rank, cf.<key>
+	Order     string // asc, desc
+	Cursor    string
+	Limit     int  // 0 = sem limite (uso interno)
+	WithTotal bool // calcula o total de tasks que batem com os filtros
+
+	sortField *CustomField // preenchido quando Sort é cf.<key>
+	expr      *queryExpr   // Expr compilada em prepareFilter
+}
+
+// CustomFieldFilter é um filtro sobre campo customizado. Op: eq, gt, gte,
+// lt, lte (number/date) ou contains (text/url). field e value são
+// preenchidos por resolveCustomFieldFilters.
+type CustomFieldFilter struct {
+	Key   string
+	Op    string
+	Value string
+
+	field *CustomField
+	value any
+}
+
+type TaskPage struct {
+	Items      []Task `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+type CreateChecklistItemInput struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// BulkChecklistInput aceita uma lista de títulos — mesmo formato dos
+// "bullets" retornados pelo ImproveDescription da IA.
+type BulkChecklistInput struct {
+	Items []string `json:"items" binding:"required"`
+}
+
+type UpdateChecklistItemInput struct {
+	Title    *string `json:"title"`
+	Done     *bool   `json:"done"`
+	Position *int    `json:"position"`
+}
+
+type AddDependencyInput struct {
+	BlockedByID uint `json:"blocked_by_id" binding:"required"`
+}
+
+type TaskDependencies struct {
+	BlockedBy []Task `json:"blocked_by"`
+	Blocking  []Task `json:"blocking"`
+}
+
+type CreateWorkflowStateInput struct {
+	Name string   `json:"name" binding:"required"`
+	From []string `json:"from"` // estados que podem ir para o novo estado
+	To   []string `json:"to"`   // estados para onde o novo estado pode ir
+}
+
+type WorkflowTransitionInput struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+type CommentInput struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
+
+// ReminderInput aceita exatamente um dos dois campos.
+type ReminderInput struct {
+	RemindAt      *time.Time `json:"remind_at"`
+	OffsetMinutes *int       `json:"offset_minutes"`
+}
+
+type StopTimerInput struct {
+	Note *string `json:"note" binding:"omitempty,max=2000"`
+}
+
+// TimeEntryInput é um lançamento manual: ended_at ou duration_minutes.
+type TimeEntryInput struct {
+	StartedAt       time.Time  `json:"started_at" binding:"required"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Note            string     `json:"note" binding:"max=2000"`
+}
+
+// BulkTaskInput aplica a mesma operação a uma lista de IDs ou a todas as
+// tasks que batem com Filter.
+type BulkTaskInput struct {
+	Action  string           `json:"action" binding:"required,oneof=update delete"`
+	IDs     []uint           `json:"ids"`
+	Filter  *BulkFilterInput `json:"filter"`
+	Changes BulkChangesInput `json:"changes"`
+}
+
+type BulkFilterInput struct {
+	Status       string     `json:"status"`
+	Priority     string     `json:"priority"`
+	Tags         string     `json:"tags"`
+	Query        string     `json:"q"`
+	ProjectID    *uint      `json:"project_id"`
+	DueBefore    *time.Time `json:"due_before"`
+	DueAfter     *time.Time `json:"due_after"`
+	Overdue      bool       `json:"overdue"`
+	CreatedSince *time.Time `json:"created_since"`
+}
+
+type BulkChangesInput struct {
+	Status     *string    `json:"status"`
+	Priority   *string    `json:"priority"`
+	DueDate    *time.Time `json:"due_date"`
+	AddTags    []string   `json:"add_tags"`
+	RemoveTags []string   `json:"remove_tags"`
+}
+
+type BulkItemResult struct {
+	ID    uint   `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type BulkResult struct {
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+}
+
+type CreateProjectInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type UpdateProjectInput struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Color       *string `json:"color"`
+	Description *string `json:"description"`
+	Archived    *bool   `json:"archived"`
+}
+
+type CreateCustomFieldInput struct {
+	Name     string   `json:"name" binding:"required,max=100"`
+	Key      string   `json:"key" binding:"omitempty,max=64"` // gerada a partir do nome se vazia
+	Type     string   `json:"type" binding:"required"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+// UpdateCustomFieldInput: key e type não mudam depois de criados.
+type UpdateCustomFieldInput struct {
+	Name     *string   `json:"name" binding:"omitempty,max=100"`
+	Options  *[]string `json:"options"`
+	Required *bool     `json:"required"`
+}
+
+// UpdateTagInput recebe o caminho completo da tag (ex: client/acme); mudar o
+// prefixo move a tag e as filhas na árvore.
+type UpdateTagInput struct {
+	Name  *string `json:"name" binding:"omitempty,max=100"`
+	Color *string `json:"color"` // #RRGGBB, "" remove a cor
+}
+
+type MergeTagInput struct {
+	IntoID uint `json:"into_id" binding:"required"`
+}
+
+// CreateViewInput salva uma view; sem position ela vai para o fim da lista.
+type CreateViewInput struct {
+	Name     string     `json:"name" binding:"required,max=100"`
+	Filter   ViewFilter `json:"filter"`
+	Pinned   bool       `json:"pinned"`
+	Position *int       `json:"position"`
+}
+
+type UpdateViewInput struct {
+	Name     *string     `json:"name" binding:"omitempty,max=100"`
+	Filter   *ViewFilter `json:"filter"`
+	Pinned   *bool       `json:"pinned"`
+	Position *int        `json:"position"`
+}
+
+// SearchHistoryQueryInput identifica uma busca do histórico (fixar).
+type SearchHistoryQueryInput struct {
+	Query string `json:"query" binding:"required,max=200"`
+}
+
+type UpdateSearchHistorySettingsInput struct {
+	Enabled *bool `json:"enabled" binding:"required"`
 }
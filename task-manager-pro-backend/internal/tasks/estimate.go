@@ -0,0 +1,58 @@
+package tasks
+
+import (
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+)
+
+// EstimateSummary compara estimado x realizado para as tasks de um filtro.
+// ActualMinutes considera só o tempo registrado nas tasks com estimativa,
+// para a comparação com EstimateMinutes ser justa.
+type EstimateSummary struct {
+	Tasks            int64   `json:"tasks"`
+	EstimatedTasks   int64   `json:"estimated_tasks"`
+	EstimateMinutes  int64   `json:"estimate_minutes"`
+	RemainingMinutes int64   `json:"remaining_minutes"`
+	StoryPoints      float64 `json:"story_points"`
+	DoneStoryPoints  float64 `json:"done_story_points"`
+	LoggedMinutes    int64   `json:"logged_minutes"`
+	ActualMinutes    int64   `json:"actual_minutes"`
+}
+
+// SummarizeEstimates agrega estimativas e tempo registrado das tasks que
+// batem com o filtro (os mesmos filtros da listagem).
+func SummarizeEstimates(userID uint, filter TaskFilter) (*EstimateSummary, error) {
+	if err := prepareFilter(userID, &filter); err != nil {
+		return nil, err
+	}
+
+	var summary EstimateSummary
+	if err := taskFilterQuery(userID, filter).
+		Select(`COUNT(*) AS tasks,
+			COUNT(tasks.estimate_minutes) AS estimated_tasks,
+			COALESCE(SUM(tasks.estimate_minutes), 0) AS estimate_minutes,
+			COALESCE(SUM(tasks.remaining_minutes), 0) AS remaining_minutes,
+			COALESCE(SUM(tasks.story_points), 0) AS story_points,
+			COALESCE(SUM(tasks.story_points) FILTER (WHERE tasks.status = ?), 0) AS done_story_points`, StatusDone).
+		Scan(&summary).Error; err != nil {
+		return nil, err
+	}
+
+	var logged struct {
+		LoggedSeconds int64
+		ActualSeconds int64
+	}
+	if err := database.DB.
+		Table("time_entries").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id").
+		Where("time_entries.task_id IN (?)", taskFilterQuery(userID, filter).Select("tasks.id")).
+		Select(`COALESCE(SUM(` + entrySeconds + `), 0)::bigint AS logged_seconds,
+			COALESCE(SUM(` + entrySeconds + `) FILTER (WHERE tasks.estimate_minutes IS NOT NULL), 0)::bigint AS actual_seconds`).
+		Scan(&logged).Error; err != nil {
+		return nil, err
+	}
+
+	summary.LoggedMinutes = logged.LoggedSeconds / 60
+	summary.ActualMinutes = logged.ActualSeconds / 60
+
+	return &summary, nil
+}
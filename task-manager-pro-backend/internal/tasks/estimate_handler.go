@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/auth"
+)
+
+// EstimateSummaryHandler aceita os mesmos filtros de GET /api/tasks.
+func EstimateSummaryHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary, err := SummarizeEstimates(userID, filter)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSort) || isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to summarize estimates"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,228 @@
+package tasks
+
+import (
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+)
+
+// Busca textual: tasks.search_vector junta título (peso A), nomes das tags
+// (B) e descrição (C), indexados em português e inglês. A coluna é mantida
+// por triggers no banco, então qualquer escrita (inclusive bulk, merge e
+// rename de tags) já atualiza o índice.
+
+// maxSearchResults limita a busca aos resultados mais relevantes.
+const maxSearchResults = 100
+
+// Marcadores usados nos trechos destacados.
+const (
+	highlightStart = "<mark>"
+	highlightStop  = "</mark>"
+)
+
+// SearchMatch é preenchido só nos resultados da busca. Title e Description
+// são HTML: o texto da task vem escapado e só os marcadores são tags.
+type SearchMatch struct {
+	Rank        float64 `json:"rank"`
+	Title       string  `json:"title"`                 // título com os termos destacados
+	Description string  `json:"description,omitempty"` // trecho da descrição com os termos
+}
+
+var searchIndexStatements = []string{
+	`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+
+	`CREATE OR REPLACE FUNCTION task_tag_names(p_task_id bigint) RETURNS text AS $$
+		SELECT COALESCE(string_agg(replace(t.name, '/', ' '), ' '), '')
+		FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.task_id = p_task_id
+	$$ LANGUAGE sql STABLE`,
+
+	`CREATE OR REPLACE FUNCTION tasks_search_vector(p_title text, p_description text, p_tags text) RETURNS tsvector AS $$
+		SELECT setweight(to_tsvector('portuguese', COALESCE(p_title, '')), 'A') ||
+			setweight(to_tsvector('english', COALESCE(p_title, '')), 'A') ||
+			setweight(to_tsvector('portuguese', COALESCE(p_tags, '')), 'B') ||
+			setweight(to_tsvector('english', COALESCE(p_tags, '')), 'B') ||
+			setweight(to_tsvector('portuguese', COALESCE(p_description, '')), 'C') ||
+			setweight(to_tsvector('english', COALESCE(p_description, '')), 'C')
+	$$ LANGUAGE sql IMMUTABLE`,
+
+	`CREATE OR REPLACE FUNCTION tasks_search_vector_trigger() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector := tasks_search_vector(NEW.title, NEW.description, task_tag_names(NEW.id));
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS tasks_search_vector_update ON tasks`,
+	`CREATE TRIGGER tasks_search_vector_update
+		BEFORE INSERT OR UPDATE OF title, description ON tasks
+		FOR EACH ROW EXECUTE FUNCTION tasks_search_vector_trigger()`,
+
+	// tags adicionadas/removidas de uma task
+	`CREATE OR REPLACE FUNCTION task_tags_search_vector_trigger() RETURNS trigger AS $$
+	DECLARE
+		v_task_id bigint;
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			v_task_id := OLD.task_id;
+		ELSE
+			v_task_id := NEW.task_id;
+		END IF;
+		UPDATE tasks
+		SET search_vector = tasks_search_vector(title, description, task_tag_names(id))
+		WHERE id = v_task_id;
+		RETURN NULL;
+	END
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS task_tags_search_vector_update ON task_tags`,
+	`CREATE TRIGGER task_tags_search_vector_update
+		AFTER INSERT OR DELETE ON task_tags
+		FOR EACH ROW EXECUTE FUNCTION task_tags_search_vector_trigger()`,
+
+	// tag renomeada
+	`CREATE OR REPLACE FUNCTION tags_search_vector_trigger() RETURNS trigger AS $$
+	BEGIN
+		UPDATE tasks
+		SET search_vector = tasks_search_vector(title, description, task_tag_names(id))
+		WHERE id IN (SELECT task_id FROM task_tags WHERE tag_id = NEW.id);
+		RETURN NULL;
+	END
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS tags_search_vector_update ON tags`,
+	`CREATE TRIGGER tags_search_vector_update
+		AFTER UPDATE OF name ON tags
+		FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+		EXECUTE FUNCTION tags_search_vector_trigger()`,
+
+	`CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector)`,
+
+	// tasks criadas antes do índice
+	`UPDATE tasks
+	SET search_vector = tasks_search_vector(title, description, task_tag_names(id))
+	WHERE search_vector IS NULL`,
+}
+
+// migrateSearchIndex cria a coluna, as triggers e os índices GIN da busca
+// (full-text e trigramas).
+func migrateSearchIndex() error {
+	for _, stmt := range append(searchIndexStatements, fuzzyIndexStatements...) {
+		if err := database.DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchQuery casa a busca nos dois idiomas; aceita a sintaxe do
+// websearch_to_tsquery ("frase", -termo, or).
+const searchQuery = `(websearch_to_tsquery('portuguese', ?) || websearch_to_tsquery('english', ?))`
+
+// fullTextSearch busca as tasks do usuário por relevância e preenche Match
+// com o rank e os trechos destacados.
+func fullTextSearch(userID uint, query string, opts SearchOptions) ([]Task, error) {
+	headline := func(config, column, options string) string {
+		return "ts_headline('" + config + "', " + htmlEscapeSQL("tasks."+column) + ", sq.q, '" +
+			"StartSel=" + highlightStart + ", StopSel=" + highlightStop + options + "')"
+	}
+	const snippet = ", MaxWords=30, MinWords=10, MaxFragments=2"
+
+	var rows []struct {
+		ID          uint
+		Rank        float64
+		TitlePt     string
+		TitleEn     string
+		SnippetPt   string
+		SnippetEn   string
+		Description string
+	}
+	if err := taskFilterQuery(userID, TaskFilter{ProjectID: opts.ProjectID}).
+		Joins("CROSS JOIN (SELECT "+searchQuery+" AS q) sq", query, query).
+		Where("tasks.search_vector @@ sq.q").
+		Select("tasks.id, ts_rank_cd(tasks.search_vector, sq.q) AS rank, " +
+			headline("portuguese", "title", ", HighlightAll=true") + " AS title_pt, " +
+			headline("english", "title", ", HighlightAll=true") + " AS title_en, " +
+			headline("portuguese", "description", snippet) + " AS snippet_pt, " +
+			headline("english", "description", snippet) + " AS snippet_en, " +
+			"tasks.description").
+		Order("rank DESC, tasks.updated_at DESC, tasks.id DESC").
+		Limit(maxSearchResults).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchHit, len(rows))
+	for i, r := range rows {
+		hits[i] = searchHit{ID: r.ID, Match: SearchMatch{
+			Rank:  r.Rank,
+			Title: pickHighlight(r.TitlePt, r.TitleEn),
+		}}
+		if r.Description != "" {
+			hits[i].Match.Description = pickHighlight(r.SnippetPt, r.SnippetEn)
+		}
+	}
+
+	return loadSearchHits(hits)
+}
+
+// htmlEscapeSQL escapa o texto no banco antes do ts_headline, para que o
+// título e a descrição do usuário não virem HTML junto com os marcadores.
+func htmlEscapeSQL(column string) string {
+	return "replace(replace(replace(replace(replace(COALESCE(" + column + ", ''), " +
+		`'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`
+}
+
+// searchHit é um resultado da busca antes de carregar a task.
+type searchHit struct {
+	ID    uint
+	Match SearchMatch
+}
+
+// loadSearchHits carrega as tasks dos resultados mantendo a ordem por
+// relevância.
+func loadSearchHits(hits []searchHit) ([]Task, error) {
+	if len(hits) == 0 {
+		return []Task{}, nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+
+	var found []Task
+	if err := database.DB.Preload("Tags").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]Task, len(found))
+	for _, t := range found {
+		byID[t.ID] = t
+	}
+
+	tasks := make([]Task, 0, len(hits))
+	for _, h := range hits {
+		task, ok := byID[h.ID]
+		if !ok {
+			continue
+		}
+		match := h.Match
+		task.Match = &match
+		tasks = append(tasks, task)
+	}
+
+	if err := enrichTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// pickHighlight usa o destaque do idioma que encontrou algum termo; o
+// português vence quando os dois (ou nenhum) encontram.
+func pickHighlight(pt, en string) string {
+	if !strings.Contains(pt, highlightStart) && strings.Contains(en, highlightStart) {
+		return en
+	}
+	return pt
+}
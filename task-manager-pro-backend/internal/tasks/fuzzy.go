@@ -0,0 +1,156 @@
+package tasks
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+// Busca tolerante a erros de digitação (pg_trgm). Usada com fuzzy=true ou
+// quando a busca full-text não encontra nada.
+
+// fuzzyThreshold é a similaridade mínima (0..1); sobrescrito pela config em
+// SetFuzzyThreshold.
+var fuzzyThreshold = 0.3
+
+func SetFuzzyThreshold(threshold float64) {
+	if threshold > 0 && threshold <= 1 {
+		fuzzyThreshold = threshold
+	}
+}
+
+// Máximo de palavras da query consideradas no "você quis dizer".
+const maxSuggestionWords = 10
+
+var fuzzyIndexStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS idx_tasks_title_trgm ON tasks USING GIN (title gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_tags_name_trgm ON tags USING GIN (name gin_trgm_ops)`,
+}
+
+// fuzzySearch busca por similaridade de palavras no título e no nome das
+// tags. O threshold vai para o pg_trgm via set_config local, assim o
+// operador <% continua usando os índices de trigramas.
+func fuzzySearch(userID uint, query string, opts SearchOptions) ([]Task, error) {
+	query = strings.TrimSpace(query)
+
+	var rows []struct {
+		ID    uint
+		Score float64
+		Title string
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)",
+			strconv.FormatFloat(fuzzyThreshold, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+
+		db := tx.Model(&Task{}).Where("tasks.user_id = ?", userID)
+		if opts.ProjectID != nil {
+			db = db.Where("tasks.project_id = ?", *opts.ProjectID)
+		}
+
+		return db.
+			Select(`tasks.id, tasks.title, GREATEST(
+				word_similarity(?, tasks.title),
+				COALESCE((SELECT MAX(word_similarity(?, t.name))
+					FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+					WHERE tt.task_id = tasks.id), 0)
+			) AS score`, query, query).
+			Where(`(? <% tasks.title OR EXISTS (
+				SELECT 1 FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+				WHERE tt.task_id = tasks.id AND ? <% t.name
+			))`, query, query).
+			Order("score DESC, tasks.updated_at DESC, tasks.id DESC").
+			Limit(maxSearchResults).
+			Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchHit, len(rows))
+	for i, r := range rows {
+		// mesmo formato da busca full-text: HTML escapado
+		hits[i] = searchHit{ID: r.ID, Match: SearchMatch{Rank: r.Score, Title: html.EscapeString(r.Title)}}
+	}
+
+	return loadSearchHits(hits)
+}
+
+// didYouMean troca cada palavra da query pela palavra mais parecida do
+// vocabulário do usuário (palavras dos títulos e nomes das tags). Devolve a
+// query corrigida, ou nada se não houver o que corrigir.
+func didYouMean(userID uint, query string) ([]string, error) {
+	words, candidates := suggestionWords(query)
+	if len(candidates) == 0 {
+		return []string{}, nil
+	}
+
+	// as palavras vão como um único parâmetro (não têm espaços, vieram do
+	// strings.Fields): o gorm expande slices em (?, ?), que no ARRAY vira
+	// um registro e não uma lista
+	var rows []struct {
+		Input      string
+		Suggestion string
+	}
+	if err := database.DB.Raw(`
+		WITH vocab AS (
+			SELECT DISTINCT lower(w) AS word
+			FROM tasks, regexp_split_to_table(tasks.title, '[^[:alnum:]]+') AS w
+			WHERE tasks.user_id = ? AND tasks.deleted_at IS NULL AND length(w) >= 3
+			UNION
+			SELECT lower(name) FROM tags WHERE user_id = ?
+		)
+		SELECT DISTINCT ON (q.word) q.word AS input, v.word AS suggestion
+		FROM unnest(string_to_array(?, ' ')) AS q(word)
+		JOIN vocab v ON similarity(v.word, q.word) >= ?
+		ORDER BY q.word, similarity(v.word, q.word) DESC, v.word`,
+		userID, userID, strings.Join(candidates, " "), fuzzyThreshold).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	best := make(map[string]string, len(rows))
+	for _, r := range rows {
+		best[r.Input] = r.Suggestion
+	}
+
+	return correctQuery(words, best), nil
+}
+
+// suggestionWords separa as palavras da query (em minúsculas, no máximo
+// maxSuggestionWords) e as que valem correção (3 letras ou mais).
+func suggestionWords(query string) (words, candidates []string) {
+	words = strings.Fields(strings.ToLower(query))
+	if len(words) > maxSuggestionWords {
+		words = words[:maxSuggestionWords]
+	}
+	for _, w := range words {
+		if len([]rune(w)) >= 3 {
+			candidates = append(candidates, w)
+		}
+	}
+	return words, candidates
+}
+
+// correctQuery troca as palavras pelas sugestões; devolve lista vazia
+// quando nada muda.
+func correctQuery(words []string, best map[string]string) []string {
+	changed := false
+	corrected := make([]string, len(words))
+	for i, w := range words {
+		corrected[i] = w
+		if s, ok := best[w]; ok && s != w {
+			corrected[i] = s
+			changed = true
+		}
+	}
+	if !changed {
+		return []string{}
+	}
+	return []string{strings.Join(corrected, " ")}
+}
@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggestionWords(t *testing.T) {
+	tests := []struct {
+		query      string
+		words      []string
+		candidates []string
+	}{
+		{"", nil, nil},
+		{"Relatório Mensal", []string{"relatório", "mensal"}, []string{"relatório", "mensal"}},
+		{"ir ao  médico", []string{"ir", "ao", "médico"}, []string{"médico"}},
+		{"é já", []string{"é", "já"}, nil},
+		{"ção", []string{"ção"}, []string{"ção"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			words, candidates := suggestionWords(tt.query)
+			if len(words) != len(tt.words) || (len(words) > 0 && !reflect.DeepEqual(words, tt.words)) {
+				t.Errorf("words = %q, want %q", words, tt.words)
+			}
+			if len(candidates) != len(tt.candidates) || (len(candidates) > 0 && !reflect.DeepEqual(candidates, tt.candidates)) {
+				t.Errorf("candidates = %q, want %q", candidates, tt.candidates)
+			}
+		})
+	}
+
+	words, _ := suggestionWords(strings.Repeat("palavra ", maxSuggestionWords+5))
+	if len(words) != maxSuggestionWords {
+		t.Errorf("got %d words, want at most %d", len(words), maxSuggestionWords)
+	}
+}
+
+func TestCorrectQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		best  map[string]string
+		want  []string
+	}{
+		{"no suggestions", []string{"relatorio"}, nil, []string{}},
+		{"same word", []string{"relatorio"}, map[string]string{"relatorio": "relatorio"}, []string{}},
+		{"one word", []string{"relatoro", "mensal"}, map[string]string{"relatoro": "relatorio"}, []string{"relatorio mensal"}},
+		{"keeps short words", []string{"ir", "ao", "medco"}, map[string]string{"medco": "medico"}, []string{"ir ao medico"}},
+		{"every word", []string{"relatoro", "mensl"}, map[string]string{"relatoro": "relatorio", "mensl": "mensal"}, []string{"relatorio mensal"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := correctQuery(tt.words, tt.best)
+			if got == nil {
+				t.Fatal("correctQuery returned nil, want an empty list")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("correctQuery(%q) = %q, want %q", tt.words, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchOptionsCacheSuffix(t *testing.T) {
+	one, two := uint(1), uint(2)
+	suffixes := map[string]SearchOptions{
+		"none":          {},
+		"fuzzy":         {Fuzzy: true},
+		"project 1":     {ProjectID: &one},
+		"project 2":     {ProjectID: &two},
+		"project fuzzy": {ProjectID: &one, Fuzzy: true},
+	}
+
+	seen := map[string]string{}
+	for name, opts := range suffixes {
+		suffix := opts.cacheSuffix()
+		if other, ok := seen[suffix]; ok {
+			t.Errorf("%s and %s share the cache suffix %q", name, other, suffix)
+		}
+		seen[suffix] = name
+	}
+	if (SearchOptions{}).cacheSuffix() != "" {
+		t.Error("default options should not change the cache key")
+	}
+}
@@ -1,8 +1,12 @@
 package tasks
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"errors"
 
@@ -13,6 +17,25 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// isValidationError indica erros de entrada do usuário (respondidos com 400).
+func isValidationError(err error) bool {
+	return errors.Is(err, ErrInvalidRecurrence) ||
+		errors.Is(err, ErrInvalidStatus) ||
+		errors.Is(err, ErrInvalidPriority) ||
+		errors.Is(err, ErrIllegalTransition) ||
+		errors.Is(err, ErrInvalidProject) ||
+		errors.Is(err, ErrInvalidColor) ||
+		errors.Is(err, ErrInvalidMove) ||
+		errors.Is(err, ErrInvalidReminder) ||
+		errors.Is(err, ErrInvalidTimeEntry) ||
+		errors.Is(err, ErrInvalidTimeRange) ||
+		errors.Is(err, ErrInvalidCustomField) ||
+		errors.Is(err, ErrInvalidTag) ||
+		errors.Is(err, ErrInvalidView) ||
+		errors.Is(err, ErrInvalidQuery) ||
+		errors.Is(err, ErrInvalidChecklistItem)
+}
+
 func CreateTaskHandler(c *gin.Context) {
 	userID, ok := auth.GetUserID(c)
 	if !ok {
@@ -28,7 +51,11 @@ func CreateTaskHandler(c *gin.Context) {
 
 	task, err := CreateTask(userID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
+		}
 		return
 	}
 
@@ -58,7 +85,16 @@ func UpdateTaskHandler(c *gin.Context) {
 
 	task, err := UpdateTask(userID, id, input)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		switch {
+		case isValidationError(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrTaskBlocked):
+			c.JSON(http.StatusConflict, gin.H{"error": "task is blocked by unfinished dependencies and cannot be marked as DONE"})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update task"})
+		}
 		return
 	}
 
@@ -124,20 +160,118 @@ func ListarTaskHandler(c *gin.Context) {
 		return
 	}
 
-	filter := TaskFilter{
-		Status:   c.Query("status"),
-		Priority: c.Query("priority"),
-		Tags:     c.Query("tags"),
-		Query:    c.Query("q"),
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	tasks, err := ListTasks(userID, filter)
+	page, err := ListTasks(userID, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tasks"})
+		var queryErr *QueryError
+		if errors.As(err, &queryErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "position": queryErr.Pos})
+			return
+		}
+		if errors.Is(err, ErrInvalidCursor) || errors.Is(err, ErrInvalidSort) || isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tasks"})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	c.JSON(http.StatusOK, page)
+}
+
+// taskFilterFromQuery lê filtros, ordenação e paginação da query string.
+func taskFilterFromQuery(c *gin.Context) (TaskFilter, error) {
+	filter := TaskFilter{
+		Status:    c.Query("status"),
+		Priority:  c.Query("priority"),
+		Tags:      c.Query("tags"),
+		Query:     c.Query("q"),
+		Expr:      c.Query("query"),
+		Sort:      c.Query("sort"),
+		Order:     c.Query("order"),
+		Cursor:    c.Query("cursor"),
+		Overdue:   c.Query("overdue") == "true",
+		WithTotal: c.Query("with_total") == "true",
+		Limit:     DefaultPageSize,
+	}
+
+	if raw := c.Query("project_id"); raw != "" {
+		projectID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("project_id must be a number")
+		}
+		id := uint(projectID)
+		filter.ProjectID = &id
+	}
+
+	limit, err := limitFromQuery(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.Limit = limit
+
+	// cf.<key>=valor ou cf.<key>.<op>=valor
+	for param, values := range c.Request.URL.Query() {
+		rest, ok := strings.CutPrefix(param, "cf.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		key, op, _ := strings.Cut(rest, ".")
+		filter.CustomFields = append(filter.CustomFields, CustomFieldFilter{Key: key, Op: op, Value: values[0]})
+	}
+	sortCustomFieldFilters(filter.CustomFields)
+
+	dates := map[string]**time.Time{
+		"due_before":    &filter.DueBefore,
+		"due_after":     &filter.DueAfter,
+		"created_since": &filter.CreatedSince,
+	}
+	for param, dst := range dates {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		t, err := parseDateParam(raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be a date (YYYY-MM-DD or RFC3339)", param)
+		}
+		*dst = &t
+	}
+
+	return filter, nil
+}
+
+// limitFromQuery lê o tamanho da página (DefaultPageSize se ausente).
+func limitFromQuery(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return DefaultPageSize, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > MaxPageSize {
+		return 0, fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	}
+	return limit, nil
+}
+
+// sortCustomFieldFilters deixa os filtros de campo customizado em ordem
+// estável, independente da ordem dos parâmetros.
+func sortCustomFieldFilters(filters []CustomFieldFilter) {
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].Key+"."+filters[i].Op < filters[j].Key+"."+filters[j].Op
+	})
+}
+
+func parseDateParam(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
 }
 
 func SearchTasksHandler(c *gin.Context) {
@@ -153,27 +287,73 @@ func SearchTasksHandler(c *gin.Context) {
 		return
 	}
 
-	tasks, err := SearchTasks(userID, query)
+	opts := SearchOptions{Fuzzy: c.Query("fuzzy") == "true"}
+	if raw := c.Query("project_id"); raw != "" {
+		projectID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project_id must be a number"})
+			return
+		}
+		id := uint(projectID)
+		opts.ProjectID = &id
+	}
+
+	result, err := SearchTasks(userID, query, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search tasks"})
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	c.JSON(http.StatusOK, result)
 }
 
-func GetSearchHistoryHandler(c *gin.Context) {
+// SuggestSearchHandler completa o que o usuário está digitando na busca
+// (?prefix=, ?limit= até MaxSuggestLimit).
+func SuggestSearchHandler(c *gin.Context) {
 	userID, ok := auth.GetUserID(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	history, err := GetSearchHistory(uint(userID))
+	prefix := c.Query("prefix")
+	if strings.TrimSpace(prefix) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing query parameter 'prefix'"})
+		return
+	}
+
+	limit := DefaultSuggestLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > MaxSuggestLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", MaxSuggestLimit)})
+			return
+		}
+		limit = n
+	}
+
+	suggestions, err := SuggestSearch(userID, prefix, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch search history"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to suggest searches"})
 		return
 	}
 
-	c.JSON(http.StatusOK, history)
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// InvalidateSearchCacheOnWrite invalida o cache de busca do usuário depois de
+// toda escrita bem-sucedida (qualquer método além de GET/HEAD) nas rotas em
+// que é usado. Roda depois do handler, então a transação já foi commitada.
+func InvalidateSearchCacheOnWrite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		method := c.Request.Method
+		if method == http.MethodGet || method == http.MethodHead || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		if userID, ok := auth.GetUserID(c); ok {
+			InvalidateSearchCache(userID)
+		}
+	}
 }
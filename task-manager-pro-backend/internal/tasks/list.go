@@ -0,0 +1,266 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort field")
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Tasks sem due_date vão para o fim na ordenação ascendente. O literal SQL
+// leva o fuso explícito para bater com noDueDate em qualquer TimeZone da sessão.
+const noDueDateSQL = "'9999-12-31 00:00:00+00'::timestamptz"
+
+var noDueDate = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// sortColumns mapeia o campo público para a expressão SQL usada no ORDER BY
+// e na condição do cursor.
+var sortColumns = map[string]string{
+	"created_at": "tasks.created_at",
+	"updated_at": "tasks.updated_at",
+	"due_date":   "COALESCE(tasks.due_date, " + noDueDateSQL + ")",
+	"title":      "tasks.title",
+	"rank":       rankOrder,
+	"priority":   "CASE tasks.priority WHEN 'HIGH' THEN 3 WHEN 'MEDIUM' THEN 2 WHEN 'LOW' THEN 1 ELSE 0 END",
+}
+
+// taskCursor é serializado em base64 e devolvido como next_cursor opaco.
+type taskCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// prepareFilter compila a query (?query=), normaliza a ordenação e resolve
+// os campos customizados usados em filtros e ordenação.
+func prepareFilter(userID uint, filter *TaskFilter) error {
+	if filter.Expr != "" {
+		expr, err := compileQuery(userID, filter.Expr, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.expr = expr
+	}
+
+	filter.Sort = strings.ToLower(strings.TrimSpace(filter.Sort))
+	if len(filter.CustomFields) == 0 && !strings.HasPrefix(filter.Sort, "cf.") {
+		return normalizeSort(filter)
+	}
+
+	defs, err := loadCustomFields(database.DB, userID)
+	if err != nil {
+		return err
+	}
+
+	if key, ok := strings.CutPrefix(filter.Sort, "cf."); ok {
+		field, ok := defs[key]
+		if !ok {
+			return fmt.Errorf("%w: unknown custom field %q", ErrInvalidSort, key)
+		}
+		filter.sortField = field
+	}
+
+	if err := resolveCustomFieldFilters(defs, filter); err != nil {
+		return err
+	}
+
+	return normalizeSort(filter)
+}
+
+func normalizeSort(filter *TaskFilter) error {
+	filter.Sort = strings.ToLower(strings.TrimSpace(filter.Sort))
+	if filter.Sort == "" {
+		filter.Sort = "created_at"
+	}
+	if _, ok := sortColumns[filter.Sort]; !ok && filter.sortField == nil {
+		return fmt.Errorf("%w %q", ErrInvalidSort, filter.Sort)
+	}
+
+	filter.Order = strings.ToLower(strings.TrimSpace(filter.Order))
+	if filter.Order == "" {
+		// datas mais recentes primeiro; o resto em ordem natural
+		switch filter.Sort {
+		case "created_at", "updated_at", "priority":
+			filter.Order = "desc"
+		default:
+			filter.Order = "asc"
+		}
+	}
+	if filter.Order != "asc" && filter.Order != "desc" {
+		return fmt.Errorf("%w: order must be 'asc' or 'desc'", ErrInvalidSort)
+	}
+
+	return nil
+}
+
+// sortExpr devolve a expressão SQL de ordenação do filtro.
+func sortExpr(filter TaskFilter) string {
+	if filter.sortField != nil {
+		return customFieldSortExpr(filter.sortField)
+	}
+	return sortColumns[filter.Sort]
+}
+
+// customFieldSortExpr ordena pelo valor do campo; tasks sem valor ficam no
+// fim na ordem ascendente (como due_date).
+func customFieldSortExpr(field *CustomField) string {
+	value := fmt.Sprintf("(SELECT v.%s FROM task_field_values v WHERE v.task_id = tasks.id AND v.field_id = %d)",
+		fieldColumn(field.Type), field.ID)
+
+	switch field.Type {
+	case FieldNumber:
+		return "COALESCE(" + value + ", 'Infinity'::float8)"
+	case FieldDate:
+		return "COALESCE(" + value + ", " + noDueDateSQL + ")"
+	case FieldCheckbox:
+		return "COALESCE(" + value + ", false)::int"
+	default:
+		return "COALESCE(" + value + ", '')"
+	}
+}
+
+func customFieldCursorValue(t *Task, field *CustomField) string {
+	value := t.CustomFields[field.Key]
+	switch field.Type {
+	case FieldNumber:
+		n, ok := value.(float64)
+		if !ok {
+			n = math.Inf(1)
+		}
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	case FieldDate:
+		d, ok := value.(time.Time)
+		if !ok {
+			d = noDueDate
+		}
+		return d.UTC().Format(time.RFC3339Nano)
+	case FieldCheckbox:
+		if b, _ := value.(bool); b {
+			return "1"
+		}
+		return "0"
+	default:
+		s, _ := value.(string)
+		return s
+	}
+}
+
+// cursorValue extrai da task o valor do campo de ordenação.
+func cursorValue(t *Task, filter TaskFilter) string {
+	if filter.sortField != nil {
+		return customFieldCursorValue(t, filter.sortField)
+	}
+
+	switch filter.Sort {
+	case "updated_at":
+		return t.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case "due_date":
+		if t.DueDate == nil {
+			return noDueDate.Format(time.RFC3339Nano)
+		}
+		return t.DueDate.UTC().Format(time.RFC3339Nano)
+	case "title":
+		return t.Title
+	case "rank":
+		return t.Rank
+	case "priority":
+		switch t.Priority {
+		case "HIGH":
+			return "3"
+		case "MEDIUM":
+			return "2"
+		case "LOW":
+			return "1"
+		}
+		return "0"
+	default:
+		return t.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+func encodeCursor(t *Task, filter TaskFilter) string {
+	b, _ := json.Marshal(taskCursor{
+		Sort:  filter.Sort,
+		Order: filter.Order,
+		Value: cursorValue(t, filter),
+		ID:    t.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// applyCursor adiciona a condição de keyset pagination (valor, id).
+func applyCursor(db *gorm.DB, filter TaskFilter) (*gorm.DB, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cur taskCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cur.Sort != filter.Sort || cur.Order != filter.Order {
+		return nil, fmt.Errorf("%w: cursor was created with a different sort", ErrInvalidCursor)
+	}
+
+	kind := cur.Sort
+	if filter.sortField != nil {
+		kind = "cf." + filter.sortField.Type
+	}
+
+	var value any = cur.Value
+	switch kind {
+	case "cf." + FieldNumber:
+		n, err := strconv.ParseFloat(cur.Value, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		value = n
+	case "created_at", "updated_at", "due_date", "cf." + FieldDate:
+		t, err := time.Parse(time.RFC3339Nano, cur.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		value = t
+	case "priority", "cf." + FieldCheckbox:
+		var n int
+		if _, err := fmt.Sscan(cur.Value, &n); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		value = n
+	}
+
+	op := ">"
+	if filter.Order == "desc" {
+		op = "<"
+	}
+	expr := sortExpr(filter)
+
+	return db.Where(
+		fmt.Sprintf("(%s %s ?) OR (%s = ? AND tasks.id %s ?)", expr, op, expr, op),
+		value, value, cur.ID,
+	), nil
+}
+
+func orderClause(filter TaskFilter) string {
+	dir := strings.ToUpper(filter.Order)
+	return fmt.Sprintf("%s %s, tasks.id %s", sortExpr(filter), dir, dir)
+}
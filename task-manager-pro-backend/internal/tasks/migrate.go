@@ -2,16 +2,63 @@ package tasks
 
 import (
 	"log"
+	"strings"
 
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
 )
 
 func Migrate() {
-	err := database.DB.AutoMigrate(&Tag{}, &Task{})
+	// idx_user_tag era único só no nome (dois usuários não podiam ter a mesma
+	// tag); o AutoMigrate não altera índice existente, então recria
+	var tagIndex string
+	if err := database.DB.Raw(
+		"SELECT indexdef FROM pg_indexes WHERE tablename = 'tags' AND indexname = 'idx_user_tag'",
+	).Scan(&tagIndex).Error; err != nil {
+		log.Fatal("Failed to inspect tags indexes:", err)
+	}
+	if tagIndex != "" && !strings.Contains(tagIndex, "user_id") {
+		if err := database.DB.Migrator().DropIndex(&Tag{}, "idx_user_tag"); err != nil {
+			log.Fatal("Failed to drop old tags index:", err)
+		}
+	}
+
+	// tasks de antes do board ficaram com rank NULL; o AutoMigrate não
+	// consegue aplicar o NOT NULL com elas
+	if database.DB.Migrator().HasColumn(&Task{}, "rank") {
+		if err := database.DB.Exec("UPDATE tasks SET rank = '' WHERE rank IS NULL").Error; err != nil {
+			log.Fatal("Failed to backfill task ranks:", err)
+		}
+	}
+
+	err := database.DB.AutoMigrate(
+		&Tag{}, &Project{}, &Task{}, &ChecklistItem{}, &TaskDependency{},
+		&WorkflowState{}, &WorkflowTransition{}, &TaskStatusTransition{},
+		&TaskActivity{}, &TaskComment{}, &TaskAttachment{}, &TaskReminder{},
+		&TimeEntry{}, &CustomField{}, &TaskFieldValue{}, &SavedView{},
+		&SearchPreference{},
+	)
 	if err != nil {
 		log.Fatal("Failed to migrate tasks/tags tables:", err)
 	}
 
-	log.Println("Tasks & Tags tables migrated")
+	// Normaliza status legados ("DOING", "IN PROGRESS", ...) para o workflow
+	if err := normalizeTaskStatuses(database.DB); err != nil {
+		log.Fatal("Failed to normalize task statuses:", err)
+	}
+
+	if err := backfillRecurrenceCounts(database.DB); err != nil {
+		log.Fatal("Failed to backfill recurrence counts:", err)
+	}
+
+	if err := migrateSearchIndex(); err != nil {
+		log.Fatal("Failed to migrate tasks search index:", err)
+	}
+
+	// tags "client/acme" criadas antes da hierarquia ganham os pais
+	if err := linkTagParents(database.DB); err != nil {
+		log.Fatal("Failed to link tag parents:", err)
+	}
+
+	log.Println("Tasks, Tags, Checklist, Dependency & Workflow tables migrated")
 
 }
@@ -2,17 +2,38 @@ package tasks
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Task struct {
-	ID          uint       `json:"id" gorm:"primaryKey"`
-	UserID      uint       `json:"user_id" gorm:"index"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Priority    string     `json:"priority"` // LOW, MEDIUM, HIGH
-	Status      string     `json:"status"`   // TODO, IN_PROGRESS, DONE
-	DueDate     *time.Time `json:"due_date"`
-	Tags        []Tag      `json:"tags" gorm:"many2many:task_tags;"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID               uint            `json:"id" gorm:"primaryKey"`
+	UserID           uint            `json:"user_id" gorm:"index"`
+	ParentID         *uint           `json:"parent_id" gorm:"index"`
+	ProjectID        *uint           `json:"project_id" gorm:"index"`
+	Title            string          `json:"title"`
+	Description      string          `json:"description"`
+	Priority         string          `json:"priority"` // LOW, MEDIUM, HIGH
+	Status           string          `json:"status"`   // TODO, IN_PROGRESS, DONE
+	Rank             string          `json:"rank" gorm:"size:64;not null;default:'';index"`
+	DueDate          *time.Time      `json:"due_date"`
+	EstimateMinutes  *int            `json:"estimate_minutes"`
+	StoryPoints      *float64        `json:"story_points"`
+	RemainingMinutes *int            `json:"remaining_minutes"`
+	Recurrence       string          `json:"recurrence,omitempty"` // RRULE, ex: FREQ=WEEKLY;BYDAY=MO
+	SeriesID         *uint           `json:"series_id,omitempty" gorm:"index"`
+	NextOccurrenceID *uint           `json:"next_occurrence_id,omitempty"`
+	RecurrenceEnded  bool            `json:"-" gorm:"not null;default:false"`
+	RecurrenceErrors int             `json:"-" gorm:"not null;default:0"`
+	RecurrenceCount  int             `json:"-" gorm:"not null;default:0"` // ocorrências já geradas (só na raiz da série)
+	Tags             []Tag           `json:"tags" gorm:"many2many:task_tags;"`
+	Checklist        []ChecklistItem `json:"checklist,omitempty" gorm:"foreignKey:TaskID"`
+	Progress         *TaskProgress   `json:"progress,omitempty" gorm:"-"`
+	Blocked          bool            `json:"blocked" gorm:"-"`
+	CommentCount     int64           `json:"comment_count" gorm:"-"`
+	CustomFields     map[string]any  `json:"custom_fields" gorm:"-"` // key -> valor
+	Match            *SearchMatch    `json:"match,omitempty" gorm:"-"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index"`
 }
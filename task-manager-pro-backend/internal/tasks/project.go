@@ -0,0 +1,223 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidProject = errors.New("invalid project")
+	ErrProjectExists  = errors.New("a project with this name already exists")
+	ErrInvalidColor   = errors.New("color must be in the #RRGGBB format")
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func normalizeColor(color string) (string, error) {
+	color = strings.TrimSpace(color)
+	if color == "" {
+		return "", nil
+	}
+	if !colorPattern.MatchString(color) {
+		return "", ErrInvalidColor
+	}
+	return strings.ToLower(color), nil
+}
+
+// validateTaskProject garante que o projeto é do usuário e não está arquivado.
+// projectID 0 significa "sem projeto".
+func validateTaskProject(db *gorm.DB, userID uint, projectID *uint) (*uint, error) {
+	if projectID == nil || *projectID == 0 {
+		return nil, nil
+	}
+
+	var project Project
+	if err := db.Where("id = ? AND user_id = ?", *projectID, userID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: project %d not found", ErrInvalidProject, *projectID)
+		}
+		return nil, err
+	}
+	if project.Archived {
+		return nil, fmt.Errorf("%w: project %q is archived", ErrInvalidProject, project.Name)
+	}
+
+	id := project.ID
+	return &id, nil
+}
+
+func nameTaken(db *gorm.DB, userID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Model(&Project{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func ListProjects(userID uint, includeArchived bool) ([]Project, error) {
+	db := database.DB.Where("user_id = ?", userID)
+	if !includeArchived {
+		db = db.Where("archived = ?", false)
+	}
+
+	projects := []Project{}
+	if err := db.Order("name ASC").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	if err := attachProjectCounts(userID, projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func GetProject(userID uint, id uint) (*Project, error) {
+	var project Project
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	list := []Project{project}
+	if err := attachProjectCounts(userID, list); err != nil {
+		return nil, err
+	}
+
+	return &list[0], nil
+}
+
+func CreateProject(userID uint, input CreateProjectInput) (*Project, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name must not be empty", ErrInvalidProject)
+	}
+
+	color, err := normalizeColor(input.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	taken, err := nameTaken(database.DB, userID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrProjectExists
+	}
+
+	project := &Project{
+		UserID:       userID,
+		Name:         name,
+		Color:        color,
+		Description:  input.Description,
+		StatusCounts: map[string]int64{},
+	}
+	if err := database.DB.Create(project).Error; err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func UpdateProject(userID uint, id uint, input UpdateProjectInput) (*Project, error) {
+	var project Project
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name must not be empty", ErrInvalidProject)
+		}
+		taken, err := nameTaken(database.DB, userID, name, project.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrProjectExists
+		}
+		project.Name = name
+	}
+	if input.Color != nil {
+		color, err := normalizeColor(*input.Color)
+		if err != nil {
+			return nil, err
+		}
+		project.Color = color
+	}
+	if input.Description != nil {
+		project.Description = *input.Description
+	}
+	if input.Archived != nil {
+		project.Archived = *input.Archived
+	}
+
+	if err := database.DB.Save(&project).Error; err != nil {
+		return nil, err
+	}
+
+	return GetProject(userID, project.ID)
+}
+
+// DeleteProject remove o projeto; as tasks (inclusive as da lixeira) ficam
+// sem projeto em vez de serem apagadas.
+func DeleteProject(userID uint, id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&Project{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Unscoped().Model(&Task{}).
+			Where("user_id = ? AND project_id = ?", userID, id).
+			Update("project_id", nil).Error
+	})
+}
+
+// attachProjectCounts preenche a contagem de tasks por status de cada projeto.
+func attachProjectCounts(userID uint, projects []Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+
+	var rows []struct {
+		ProjectID uint
+		Status    string
+		Total     int64
+	}
+	if err := database.DB.Model(&Task{}).
+		Select("project_id, status, COUNT(*) AS total").
+		Where("user_id = ? AND project_id IN ?", userID, ids).
+		Group("project_id, status").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	index := map[uint]int{}
+	for i := range projects {
+		projects[i].StatusCounts = map[string]int64{}
+		projects[i].TaskCount = 0
+		index[projects[i].ID] = i
+	}
+	for _, r := range rows {
+		p := &projects[index[r.ProjectID]]
+		p.StatusCounts[r.Status] = r.Total
+		p.TaskCount += r.Total
+	}
+
+	return nil
+}
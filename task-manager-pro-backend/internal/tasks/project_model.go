@@ -0,0 +1,18 @@
+package tasks
+
+import "time"
+
+type Project struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"index:idx_user_project,unique"`
+	Name        string    `json:"name" gorm:"size:100;index:idx_user_project,unique"`
+	Color       string    `json:"color" gorm:"size:7"` // #RRGGBB
+	Description string    `json:"description" gorm:"type:text"`
+	Archived    bool      `json:"archived" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Contagem de tasks por status (calculada)
+	StatusCounts map[string]int64 `json:"status_counts" gorm:"-"`
+	TaskCount    int64            `json:"task_count" gorm:"-"`
+}
@@ -0,0 +1,547 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Linguagem de busca aceita em GET /api/tasks?query=
+//
+//	status:todo priority:high tag:work -tag:later due<7d "frase exata" OR ...
+//
+// Termos separados por espaço são combinados com AND; OR tem precedência
+// menor que AND; "-" nega o termo seguinte e parênteses agrupam. A negação
+// inclui as tasks sem o campo: -project:12 traz também as sem projeto.
+//
+// Campos:
+//
+//	status:todo,doing        status (lista separada por vírgula)
+//	priority:high            priority; aceita < <= > >= (low < medium < high)
+//	tag:work  tag:client/*   tag exata ou subárvore
+//	project:12  project:Site project:none
+//	due created updated      datas com : < <= > >=; valores YYYY-MM-DD,
+//	                         RFC3339, today, tomorrow, yesterday ou relativos
+//	                         (12h, 7d, 2w). Relativos apontam para o futuro em
+//	                         due e para o passado em created/updated, então
+//	                         due<7d vence antes de daqui a 7 dias e created>7d
+//	                         foi criada nos últimos 7 dias. due:none = sem prazo.
+//	is:overdue|done|open|blocked|subtask
+//	has:due|tags|project|estimate|description
+//
+// Palavras e "frases" soltas buscam em título, descrição e nome das tags.
+// Para buscar um texto com ":" literalmente, use aspas.
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+const (
+	maxQueryLength = 1000
+	maxQueryDepth  = 20
+)
+
+// QueryError aponta a posição (1 = primeiro caractere) do problema na query.
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s at position %d: %s", ErrInvalidQuery, e.Pos, e.Msg)
+}
+
+func (e *QueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// queryExpr é a query compilada: SQL com placeholders e os argumentos.
+type queryExpr struct {
+	sql  string
+	args []any
+}
+
+type queryTokenKind int
+
+const (
+	tokEOF queryTokenKind = iota
+	tokWord
+	tokPhrase
+	tokMinus
+	tokLParen
+	tokRParen
+	tokOr
+	tokAnd
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+func lexQuery(input string) ([]queryToken, error) {
+	runes := []rune(input)
+	var tokens []queryToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, text: "(", pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, text: ")", pos: i + 1})
+			i++
+		case r == '-' && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			tokens = append(tokens, queryToken{kind: tokMinus, text: "-", pos: i + 1})
+			i++
+		case r == '"':
+			end := indexRune(runes, '"', i+1)
+			if end < 0 {
+				return nil, &QueryError{Pos: i + 1, Msg: "unterminated quoted phrase"}
+			}
+			tokens = append(tokens, queryToken{kind: tokPhrase, text: string(runes[i+1 : end]), pos: i + 1})
+			i = end + 1
+		default:
+			// palavra; aspas no meio (tag:"client work") fazem parte do valor
+			start := i
+			var word strings.Builder
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if runes[i] == '"' {
+					end := indexRune(runes, '"', i+1)
+					if end < 0 {
+						return nil, &QueryError{Pos: i + 1, Msg: "unterminated quoted value"}
+					}
+					word.WriteString(string(runes[i+1 : end]))
+					i = end + 1
+					continue
+				}
+				word.WriteRune(runes[i])
+				i++
+			}
+
+			tok := queryToken{kind: tokWord, text: word.String(), pos: start + 1}
+			switch string(runes[start:i]) {
+			case "OR":
+				tok.kind = tokOr
+			case "AND":
+				tok.kind = tokAnd
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+
+	return append(tokens, queryToken{kind: tokEOF, pos: len(runes) + 1}), nil
+}
+
+func indexRune(runes []rune, r rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+type queryParser struct {
+	tokens []queryToken
+	next   int
+	depth  int
+	userID uint
+	now    time.Time
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.next]
+}
+
+func (p *queryParser) advance() queryToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+// compileQuery transforma a query em uma condição SQL parametrizada; valores
+// do usuário nunca entram no SQL, só nos argumentos.
+func compileQuery(userID uint, input string, now time.Time) (*queryExpr, error) {
+	if len([]rune(input)) > maxQueryLength {
+		return nil, &QueryError{Pos: maxQueryLength + 1, Msg: fmt.Sprintf("query is longer than %d characters", maxQueryLength)}
+	}
+
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokEOF {
+		return nil, nil
+	}
+
+	p := &queryParser{tokens: tokens, userID: userID, now: now}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	expr.sql = "(" + expr.sql + ")"
+	return expr, nil
+}
+
+func (p *queryParser) parseOr() (*queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryExpr{
+			sql:  "(" + left.sql + " OR " + right.sql + ")",
+			args: append(left.args, right.args...),
+		}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (*queryExpr, error) {
+	var parts []string
+	var args []any
+
+	for {
+		tok := p.peek()
+		if tok.kind == tokAnd {
+			if len(parts) == 0 {
+				return nil, &QueryError{Pos: tok.pos, Msg: "AND must be between two terms"}
+			}
+			p.advance()
+			tok = p.peek()
+			if !startsTerm(tok) {
+				return nil, &QueryError{Pos: tok.pos, Msg: "expected a term after AND"}
+			}
+		}
+		if !startsTerm(tok) {
+			break
+		}
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, expr.sql)
+		args = append(args, expr.args...)
+	}
+
+	if len(parts) == 0 {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			return nil, &QueryError{Pos: tok.pos, Msg: "expected a term at the end of the query"}
+		}
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("expected a term before %q", tok.text)}
+	}
+	if len(parts) == 1 {
+		return &queryExpr{sql: parts[0], args: args}, nil
+	}
+	return &queryExpr{sql: "(" + strings.Join(parts, " AND ") + ")", args: args}, nil
+}
+
+func startsTerm(tok queryToken) bool {
+	switch tok.kind {
+	case tokWord, tokPhrase, tokMinus, tokLParen:
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) parseUnary() (*queryExpr, error) {
+	tok := p.advance()
+
+	switch tok.kind {
+	case tokMinus:
+		if !startsTerm(p.peek()) || p.peek().kind == tokMinus {
+			return nil, &QueryError{Pos: tok.pos, Msg: "'-' must be followed by a term"}
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		// IS NOT TRUE em vez de NOT: com NULL (sem prazo, sem projeto) a
+		// condição é desconhecida e NOT manteria a task fora do resultado
+		return &queryExpr{sql: "(" + expr.sql + ") IS NOT TRUE", args: expr.args}, nil
+
+	case tokLParen:
+		p.depth++
+		if p.depth > maxQueryDepth {
+			return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("too many nested parentheses (max %d)", maxQueryDepth)}
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, &QueryError{Pos: tok.pos, Msg: "missing closing parenthesis"}
+		}
+		p.advance()
+		p.depth--
+		return &queryExpr{sql: "(" + expr.sql + ")", args: expr.args}, nil
+
+	case tokPhrase:
+		return textCondition(tok)
+
+	default:
+		return p.term(tok)
+	}
+}
+
+var queryFieldPattern = regexp.MustCompile(`^([A-Za-z_]+)(:|<=|>=|<|>)(.*)$`)
+
+func (p *queryParser) term(tok queryToken) (*queryExpr, error) {
+	m := queryFieldPattern.FindStringSubmatch(tok.text)
+	if m == nil {
+		return textCondition(tok)
+	}
+
+	field, op, value := strings.ToLower(m[1]), m[2], m[3]
+	// posição do valor, para apontar o erro no lugar certo
+	valuePos := tok.pos + len([]rune(m[1])) + len(op)
+	if value == "" {
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("missing value for %s", field)}
+	}
+	onlyColon := func() error {
+		if op != ":" {
+			return &QueryError{Pos: valuePos - len(op), Msg: fmt.Sprintf("%s only supports ':'", field)}
+		}
+		return nil
+	}
+
+	switch field {
+	case "status":
+		if err := onlyColon(); err != nil {
+			return nil, err
+		}
+		var statuses []string
+		for _, s := range strings.Split(value, ",") {
+			if s = normalizeStatus(s); s != "" {
+				statuses = append(statuses, s)
+			}
+		}
+		if len(statuses) == 0 {
+			return nil, &QueryError{Pos: valuePos, Msg: "missing value for status"}
+		}
+		return &queryExpr{sql: "tasks.status IN ?", args: []any{statuses}}, nil
+
+	case "priority":
+		return priorityCondition(op, value, valuePos)
+
+	case "tag":
+		if err := onlyColon(); err != nil {
+			return nil, err
+		}
+		if normalizeTagPath(strings.TrimSuffix(value, "/*")) == "" {
+			return nil, &QueryError{Pos: valuePos, Msg: "missing tag name"}
+		}
+		cond, args := tagFilterCondition(value)
+		return &queryExpr{
+			sql:  "EXISTS (SELECT 1 FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.task_id = tasks.id AND " + cond + ")",
+			args: args,
+		}, nil
+
+	case "project":
+		if err := onlyColon(); err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(value, "none") {
+			return &queryExpr{sql: "tasks.project_id IS NULL"}, nil
+		}
+		if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+			return &queryExpr{sql: "tasks.project_id = ?", args: []any{uint(id)}}, nil
+		}
+		return &queryExpr{
+			sql:  "tasks.project_id IN (SELECT id FROM projects WHERE user_id = ? AND LOWER(name) = LOWER(?))",
+			args: []any{p.userID, value},
+		}, nil
+
+	case "due", "created", "updated":
+		column := "tasks.due_date"
+		if field != "due" {
+			column = "tasks." + field + "_at"
+		}
+		if field == "due" && op == ":" && strings.EqualFold(value, "none") {
+			return &queryExpr{sql: "tasks.due_date IS NULL"}, nil
+		}
+		// relativos: futuro para due, passado para created/updated
+		direction := 1
+		if field != "due" {
+			direction = -1
+		}
+		start, end, err := parseQueryDate(value, direction, p.now)
+		if err != nil {
+			return nil, &QueryError{Pos: valuePos, Msg: err.Error()}
+		}
+		return dateCondition(column, op, start, end), nil
+
+	case "is":
+		if err := onlyColon(); err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(value) {
+		case "overdue":
+			return &queryExpr{sql: "(tasks.due_date < ? AND tasks.status <> ?)", args: []any{p.now, StatusDone}}, nil
+		case "done":
+			return &queryExpr{sql: "tasks.status = ?", args: []any{StatusDone}}, nil
+		case "open":
+			return &queryExpr{sql: "tasks.status <> ?", args: []any{StatusDone}}, nil
+		case "subtask":
+			return &queryExpr{sql: "tasks.parent_id IS NOT NULL"}, nil
+		case "blocked":
+			return &queryExpr{
+				sql: `EXISTS (SELECT 1 FROM task_dependencies d
+					JOIN tasks blocker ON blocker.id = d.blocked_by_id AND blocker.deleted_at IS NULL
+					WHERE d.task_id = tasks.id AND blocker.status <> ?)`,
+				args: []any{StatusDone},
+			}, nil
+		}
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown value %q for is (allowed: overdue, done, open, blocked, subtask)", value)}
+
+	case "has":
+		if err := onlyColon(); err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(value) {
+		case "due":
+			return &queryExpr{sql: "tasks.due_date IS NOT NULL"}, nil
+		case "tags":
+			return &queryExpr{sql: "EXISTS (SELECT 1 FROM task_tags tt WHERE tt.task_id = tasks.id)"}, nil
+		case "project":
+			return &queryExpr{sql: "tasks.project_id IS NOT NULL"}, nil
+		case "estimate":
+			return &queryExpr{sql: "tasks.estimate_minutes IS NOT NULL"}, nil
+		case "description":
+			return &queryExpr{sql: "COALESCE(tasks.description, '') <> ''"}, nil
+		}
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown value %q for has (allowed: due, tags, project, estimate, description)", value)}
+	}
+
+	return nil, &QueryError{
+		Pos: tok.pos,
+		Msg: fmt.Sprintf("unknown field %q (allowed: status, priority, tag, project, due, created, updated, is, has); quote the text to search for it literally", m[1]),
+	}
+}
+
+// textCondition busca o texto em título, descrição e nome das tags.
+func textCondition(tok queryToken) (*queryExpr, error) {
+	text := strings.TrimSpace(tok.text)
+	if text == "" {
+		return nil, &QueryError{Pos: tok.pos, Msg: "empty phrase"}
+	}
+
+	q := "%" + escapeLike(text) + "%"
+	return &queryExpr{
+		sql: `(tasks.title ILIKE ? OR tasks.description ILIKE ? OR EXISTS (
+			SELECT 1 FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+			WHERE tt.task_id = tasks.id AND t.name ILIKE ?))`,
+		args: []any{q, q, q},
+	}, nil
+}
+
+func priorityCondition(op string, value string, pos int) (*queryExpr, error) {
+	if op == ":" {
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			priority, err := normalizePriority(v)
+			if err != nil {
+				return nil, &QueryError{Pos: pos, Msg: err.Error()}
+			}
+			values = append(values, priority)
+		}
+		return &queryExpr{sql: "tasks.priority IN ?", args: []any{values}}, nil
+	}
+
+	priority, err := normalizePriority(value)
+	if err != nil {
+		return nil, &QueryError{Pos: pos, Msg: err.Error()}
+	}
+	weight := map[string]int{"LOW": 1, "MEDIUM": 2, "HIGH": 3}[priority]
+	return &queryExpr{sql: sortColumns["priority"] + " " + op + " ?", args: []any{weight}}, nil
+}
+
+var relativeDatePattern = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+// parseQueryDate devolve o intervalo [start, end) que o valor representa:
+// um dia inteiro para datas sem hora, ou um instante (start == end).
+func parseQueryDate(value string, direction int, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := func(t time.Time) (time.Time, time.Time, error) {
+		return t, t.AddDate(0, 0, 1), nil
+	}
+
+	switch strings.ToLower(value) {
+	case "today":
+		return day(today)
+	case "tomorrow":
+		return day(today.AddDate(0, 0, 1))
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1))
+	}
+
+	if m := relativeDatePattern.FindStringSubmatch(strings.ToLower(value)); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n > 3650 {
+			return time.Time{}, time.Time{}, fmt.Errorf("relative date %q is too large", value)
+		}
+		unit := map[string]time.Duration{"h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[2]]
+		t := now.Add(time.Duration(direction*n) * unit)
+		return t, t, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return day(t)
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, t, nil
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD, RFC3339, today, tomorrow, yesterday or 12h/7d/2w)", value)
+}
+
+func dateCondition(column string, op string, start, end time.Time) *queryExpr {
+	instant := start.Equal(end)
+	switch op {
+	case "<":
+		return &queryExpr{sql: column + " < ?", args: []any{start}}
+	case "<=":
+		if instant {
+			return &queryExpr{sql: column + " <= ?", args: []any{start}}
+		}
+		return &queryExpr{sql: column + " < ?", args: []any{end}}
+	case ">":
+		if instant {
+			return &queryExpr{sql: column + " > ?", args: []any{start}}
+		}
+		return &queryExpr{sql: column + " >= ?", args: []any{end}}
+	case ">=":
+		return &queryExpr{sql: column + " >= ?", args: []any{start}}
+	}
+
+	// ":" com instante vale pelo dia (UTC) em que ele cai
+	if instant {
+		start = start.UTC()
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 0, 1)
+	}
+	return &queryExpr{sql: "(" + column + " >= ? AND " + column + " < ?)", args: []any{start, end}}
+}
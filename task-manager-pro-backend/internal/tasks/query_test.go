@@ -0,0 +1,242 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var queryTestNow = time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+
+func TestCompileQueryNegationKeepsNulls(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"-is:overdue", "((tasks.due_date < ? AND tasks.status <> ?)) IS NOT TRUE"},
+		{"-project:12", "(tasks.project_id = ?) IS NOT TRUE"},
+		{"-due<7d", "(tasks.due_date < ?) IS NOT TRUE"},
+		{"-(status:done OR -has:due)", "(((tasks.status IN ? OR (tasks.due_date IS NOT NULL) IS NOT TRUE))) IS NOT TRUE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := compileQuery(1, tt.query, queryTestNow)
+			if err != nil {
+				t.Fatalf("compileQuery(%q) error: %v", tt.query, err)
+			}
+			if want := "(" + tt.want + ")"; expr.sql != want {
+				t.Errorf("compileQuery(%q) sql = %s, want %s", tt.query, expr.sql, want)
+			}
+			if strings.Contains(expr.sql, "NOT (") {
+				t.Errorf("compileQuery(%q) uses plain NOT: %s", tt.query, expr.sql)
+			}
+		})
+	}
+}
+
+// shapeOf troca a condição de texto por T para comparar só a estrutura.
+func shapeOf(t *testing.T, sql string) string {
+	t.Helper()
+	text, err := textCondition(queryToken{kind: tokWord, text: "x", pos: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.ReplaceAll(sql, text.sql, "T")
+}
+
+func TestCompileQueryPrecedence(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		args  int
+	}{
+		{"a", "(T)", 3},
+		{"a b", "((T AND T))", 6},
+		{"a AND b", "((T AND T))", 6},
+		{"a OR b c", "((T OR (T AND T)))", 9},
+		{"a b OR c", "(((T AND T) OR T))", 9},
+		{"a AND b OR c", "(((T AND T) OR T))", 9},
+		{"(a OR b) c", "((((T OR T)) AND T))", 9},
+		{"-a OR b", "(((T) IS NOT TRUE OR T))", 6},
+		{"-(a b)", "((((T AND T))) IS NOT TRUE)", 6},
+		{"a OR b OR c", "(((T OR T) OR T))", 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := compileQuery(1, tt.query, queryTestNow)
+			if err != nil {
+				t.Fatalf("compileQuery(%q) error: %v", tt.query, err)
+			}
+			if got := shapeOf(t, expr.sql); got != tt.want {
+				t.Errorf("compileQuery(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+			if len(expr.args) != tt.args {
+				t.Errorf("compileQuery(%q) has %d args, want %d", tt.query, len(expr.args), tt.args)
+			}
+		})
+	}
+}
+
+func TestCompileQueryFields(t *testing.T) {
+	tests := []struct {
+		query string
+		sql   string
+		args  []any
+	}{
+		{"status:todo,doing", "(tasks.status IN ?)", []any{[]string{"TODO", "IN_PROGRESS"}}},
+		{"priority>=medium", "(" + sortColumns["priority"] + " >= ?)", []any{2}},
+		{"project:none", "(tasks.project_id IS NULL)", nil},
+		{"project:12", "(tasks.project_id = ?)", []any{uint(12)}},
+		{"due:none", "(tasks.due_date IS NULL)", nil},
+		{"due<7d", "(tasks.due_date < ?)", []any{queryTestNow.Add(7 * 24 * time.Hour)}},
+		{"created>7d", "(tasks.created_at > ?)", []any{queryTestNow.Add(-7 * 24 * time.Hour)}},
+		{"updated<=today", "(tasks.updated_at < ?)", []any{time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)}},
+		{"due:2025-03-10", "((tasks.due_date >= ? AND tasks.due_date < ?))", []any{
+			time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC),
+		}},
+		{"due>2025-03-10T12:00:00Z", "(tasks.due_date > ?)", []any{time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)}},
+		{"is:subtask", "(tasks.parent_id IS NOT NULL)", nil},
+		{"has:project", "(tasks.project_id IS NOT NULL)", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := compileQuery(1, tt.query, queryTestNow)
+			if err != nil {
+				t.Fatalf("compileQuery(%q) error: %v", tt.query, err)
+			}
+			if expr.sql != tt.sql {
+				t.Errorf("compileQuery(%q) sql = %s, want %s", tt.query, expr.sql, tt.sql)
+			}
+			if fmt.Sprint(expr.args) != fmt.Sprint(tt.args) && !(len(expr.args) == 0 && len(tt.args) == 0) {
+				t.Errorf("compileQuery(%q) args = %v, want %v", tt.query, expr.args, tt.args)
+			}
+		})
+	}
+}
+
+func TestCompileQueryValuesStayInArgs(t *testing.T) {
+	expr, err := compileQuery(1, `"'; DROP TABLE tasks; --" tag:"a'b" project:x'y`, queryTestNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(expr.sql, "DROP") || strings.Contains(expr.sql, "'b") || strings.Contains(expr.sql, "x'y") {
+		t.Errorf("user values leaked into the SQL: %s", expr.sql)
+	}
+}
+
+func TestCompileQueryEmpty(t *testing.T) {
+	for _, q := range []string{"", "   "} {
+		expr, err := compileQuery(1, q, queryTestNow)
+		if err != nil || expr != nil {
+			t.Errorf("compileQuery(%q) = %v, %v; want nil, nil", q, expr, err)
+		}
+	}
+}
+
+func TestCompileQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`bad"`, 4, "unterminated quoted value"},
+		{`"open`, 1, "unterminated quoted phrase"},
+		{"foo:bar", 1, `unknown field "foo"`},
+		{"OR a", 1, `expected a term before "OR"`},
+		{"a OR", 5, "expected a term at the end of the query"},
+		{"AND a", 1, "AND must be between two terms"},
+		{"a AND", 6, "expected a term after AND"},
+		{"x (a", 3, "missing closing parenthesis"},
+		{"a)", 2, `unexpected ")"`},
+		{"()", 2, `expected a term before ")"`},
+		{"-", 1, "'-' must be followed by a term"},
+		{"a -)", 3, "'-' must be followed by a term"},
+		{"status:", 8, "missing value for status"},
+		{"priority:urgent", 10, `invalid priority "urgent"`},
+		{"x due<soon", 7, `invalid date "soon"`},
+		{"is:late", 4, `unknown value "late" for is`},
+		{"has:x", 5, `unknown value "x" for has`},
+		{"tag<a", 4, "tag only supports ':'"},
+		{"status>todo", 7, "status only supports ':'"},
+		{`"  "`, 1, "empty phrase"},
+		{"due<99999d", 5, `relative date "99999d" is too large`},
+		{strings.Repeat("(", maxQueryDepth+1) + "a" + strings.Repeat(")", maxQueryDepth+1), maxQueryDepth + 1, "too many nested parentheses"},
+		{strings.Repeat("a", maxQueryLength+1), maxQueryLength + 1, "query is longer than"},
+	}
+
+	for _, tt := range tests {
+		name := tt.query
+		if len(name) > 30 {
+			name = name[:30]
+		}
+		t.Run(name, func(t *testing.T) {
+			_, err := compileQuery(1, tt.query, queryTestNow)
+			var qerr *QueryError
+			if !errors.As(err, &qerr) {
+				t.Fatalf("compileQuery(%q) error = %v, want *QueryError", tt.query, err)
+			}
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("compileQuery(%q) error does not wrap ErrInvalidQuery", tt.query)
+			}
+			if qerr.Pos != tt.pos {
+				t.Errorf("compileQuery(%q) position = %d, want %d (%s)", tt.query, qerr.Pos, tt.pos, qerr.Msg)
+			}
+			if !strings.Contains(qerr.Msg, tt.msg) {
+				t.Errorf("compileQuery(%q) message = %q, want it to contain %q", tt.query, qerr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseQueryDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		value     string
+		direction int
+		start     time.Time
+		end       time.Time
+	}{
+		{"today", 1, day(2025, 3, 10), day(2025, 3, 11)},
+		{"TOMORROW", 1, day(2025, 3, 11), day(2025, 3, 12)},
+		{"yesterday", -1, day(2025, 3, 9), day(2025, 3, 10)},
+		{"2025-02-28", 1, day(2025, 2, 28), day(2025, 3, 1)},
+		{"2025-03-10T08:30:00-03:00", 1, time.Date(2025, 3, 10, 11, 30, 0, 0, time.UTC), time.Date(2025, 3, 10, 11, 30, 0, 0, time.UTC)},
+		{"12h", 1, queryTestNow.Add(12 * time.Hour), queryTestNow.Add(12 * time.Hour)},
+		{"12h", -1, queryTestNow.Add(-12 * time.Hour), queryTestNow.Add(-12 * time.Hour)},
+		{"7d", 1, queryTestNow.AddDate(0, 0, 7), queryTestNow.AddDate(0, 0, 7)},
+		{"2W", -1, queryTestNow.AddDate(0, 0, -14), queryTestNow.AddDate(0, 0, -14)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, err := parseQueryDate(tt.value, tt.direction, queryTestNow)
+			if err != nil {
+				t.Fatalf("parseQueryDate(%q) error: %v", tt.value, err)
+			}
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("parseQueryDate(%q, %d) = [%s, %s), want [%s, %s)", tt.value, tt.direction, start, end, tt.start, tt.end)
+			}
+		})
+	}
+
+	// "today" é o dia em UTC, mesmo com now em outro fuso
+	local := time.FixedZone("UTC-3", -3*60*60)
+	start, _, err := parseQueryDate("today", 1, time.Date(2025, 3, 10, 22, 0, 0, 0, local))
+	if err != nil || !start.Equal(day(2025, 3, 11)) {
+		t.Errorf("parseQueryDate(today) at 22:00 UTC-3 = %s, %v; want %s", start, err, day(2025, 3, 11))
+	}
+
+	for _, bad := range []string{"soon", "2025-13-01", "7m", "-3d", "99999d", ""} {
+		if _, _, err := parseQueryDate(bad, 1, queryTestNow); err == nil {
+			t.Errorf("parseQueryDate(%q) accepted an invalid date", bad)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidMove = errors.New("invalid move")
+
+// Ranks são strings em base 36 comparadas byte a byte (COLLATE "C"): sempre
+// existe um rank entre dois outros, então mover uma task altera só uma linha.
+const rankAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const rankBase = len(rankAlphabet)
+
+// rankMaxLength é o tamanho a partir do qual a coluna é rebalanceada.
+const rankMaxLength = 12
+
+// rankOrder ordena pelo rank em ordem de bytes, independente da collation do banco.
+const rankOrder = `tasks.rank COLLATE "C"`
+
+func rankDigit(s string, i int) int {
+	return strings.IndexByte(rankAlphabet, s[i])
+}
+
+// rankBetween devolve um rank estritamente entre lo e hi. lo vazio é o início
+// da coluna e hi vazio é o fim. Os ranks gerados nunca terminam em '0', o que
+// garante que sempre haverá espaço entre dois deles.
+func rankBetween(lo, hi string) string {
+	var out []byte
+	bounded := hi != ""
+
+	for i := 0; ; i++ {
+		l := 0
+		if i < len(lo) {
+			l = rankDigit(lo, i)
+		}
+		h := rankBase
+		if bounded {
+			h = 0
+			if i < len(hi) {
+				h = rankDigit(hi, i)
+			}
+		}
+
+		if h-l > 1 {
+			return string(append(out, rankAlphabet[(l+h)/2]))
+		}
+
+		out = append(out, rankAlphabet[l])
+		if h-l == 1 {
+			// já ficou abaixo de hi: daqui para frente só importa lo
+			bounded = false
+		}
+	}
+}
+
+// spacedRanks gera n ranks igualmente espaçados, usados no rebalanceamento.
+func spacedRanks(n int) []string {
+	width := 1
+	space := rankBase
+	for space < (n+1)*rankBase {
+		width++
+		space *= rankBase
+	}
+	step := space / (n + 1)
+
+	ranks := make([]string, n)
+	buf := make([]byte, width)
+	for i := range ranks {
+		v := (i + 1) * step
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = rankAlphabet[v%rankBase]
+			v /= rankBase
+		}
+		ranks[i] = strings.TrimRight(string(buf), "0")
+	}
+	return ranks
+}
+
+// columnQuery devolve as tasks de uma coluna (status) do board do usuário.
+func columnQuery(db *gorm.DB, userID uint, status string) *gorm.DB {
+	return db.Model(&Task{}).Where("tasks.user_id = ? AND tasks.status = ?", userID, status)
+}
+
+// rankAtEnd devolve um rank depois da última task da coluna. Tasks ainda
+// sem rank (antes do rebalanceamento) são ignoradas.
+func rankAtEnd(db *gorm.DB, userID uint, status string) (string, error) {
+	var last []string
+	if err := columnQuery(db, userID, status).
+		Where("tasks.rank <> ''").
+		Order(rankOrder+" DESC NULLS LAST").
+		Limit(1).
+		Pluck("rank", &last).Error; err != nil {
+		return "", err
+	}
+
+	if len(last) == 0 {
+		return rankBetween("", ""), nil
+	}
+	return rankBetween(last[0], ""), nil
+}
+
+// MoveTask posiciona a task numa coluna do board, entre after_id e
+// before_id. Mudar de coluna passa pelas mesmas regras do update de status.
+func MoveTask(userID uint, id uint, input MoveTaskInput) (*Task, error) {
+	var task *Task
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if input.Status != "" {
+			status := input.Status
+			task, err = updateTask(tx, userID, id, UpdateTaskInput{Status: &status})
+		} else {
+			task, err = findUserTask(tx, userID, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		rank, err := moveRank(tx, task, input)
+		if err != nil {
+			return err
+		}
+
+		task.Rank = rank
+		return tx.Model(task).Update("rank", rank).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enrichTask(task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func moveRank(tx *gorm.DB, task *Task, input MoveTaskInput) (string, error) {
+	if input.AfterID == nil && input.BeforeID == nil {
+		return rankAtEnd(tx.Where("tasks.id <> ?", task.ID), task.UserID, task.Status)
+	}
+
+	for attempt := 0; ; attempt++ {
+		lo, hi, err := neighbourRanks(tx, task, input)
+		if err != nil {
+			return "", err
+		}
+		// vizinho informado sem rank também exige rebalanceamento
+		valid := (input.AfterID == nil || lo != "") && (input.BeforeID == nil || hi != "")
+		if valid && (hi == "" || lo < hi) {
+			return rankBetween(lo, hi), nil
+		}
+
+		// ranks repetidos ou fora de ordem (tasks antigas, cascata para
+		// DONE): rebalanceia a coluna e tenta de novo
+		if attempt > 0 {
+			return "", fmt.Errorf("%w: after_id must come before before_id", ErrInvalidMove)
+		}
+		if err := rebalanceColumn(tx, task.UserID, task.Status); err != nil {
+			return "", err
+		}
+	}
+}
+
+// neighbourRanks resolve os limites do novo rank. Com só um vizinho
+// informado, o outro limite é a task adjacente na coluna.
+func neighbourRanks(tx *gorm.DB, task *Task, input MoveTaskInput) (lo, hi string, err error) {
+	others := columnQuery(tx, task.UserID, task.Status).Where("tasks.id <> ?", task.ID).Session(&gorm.Session{})
+
+	if input.AfterID != nil {
+		if lo, err = neighbourRank(others, *input.AfterID, "after_id"); err != nil {
+			return "", "", err
+		}
+	}
+	if input.BeforeID != nil {
+		if hi, err = neighbourRank(others, *input.BeforeID, "before_id"); err != nil {
+			return "", "", err
+		}
+	}
+
+	var adjacent []string
+	switch {
+	case input.BeforeID == nil:
+		err = others.Where(rankOrder+" > ?", lo).Order(rankOrder+" ASC").Limit(1).Pluck("rank", &adjacent).Error
+		if err == nil && len(adjacent) > 0 {
+			hi = adjacent[0]
+		}
+	case input.AfterID == nil:
+		err = others.Where(rankOrder+" < ?", hi).Order(rankOrder+" DESC").Limit(1).Pluck("rank", &adjacent).Error
+		if err == nil && len(adjacent) > 0 {
+			lo = adjacent[0]
+		}
+	}
+
+	return lo, hi, err
+}
+
+func neighbourRank(others *gorm.DB, id uint, field string) (string, error) {
+	var ranks []string
+	if err := others.Where("tasks.id = ?", id).Pluck("rank", &ranks).Error; err != nil {
+		return "", err
+	}
+	if len(ranks) == 0 {
+		return "", fmt.Errorf("%w: %s must be another task in the target column", ErrInvalidMove, field)
+	}
+	return ranks[0], nil
+}
+
+// rebalanceColumn redistribui os ranks da coluna mantendo a ordem atual.
+// Tasks sem rank vão para o fim, na ordem de criação.
+func rebalanceColumn(tx *gorm.DB, userID uint, status string) error {
+	var ids []uint
+	if err := columnQuery(tx, userID, status).
+		Order("tasks.rank = '' ASC, "+rankOrder+" ASC, tasks.created_at ASC, tasks.id ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	for i, rank := range spacedRanks(len(ids)) {
+		// UpdateColumn: rebalancear não é uma alteração da task
+		if err := tx.Model(&Task{}).Where("id = ?", ids[i]).UpdateColumn("rank", rank).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebalanceRanks rebalanceia as colunas com ranks longos demais, vazios ou
+// repetidos.
+func RebalanceRanks() (int, error) {
+	var columns []struct {
+		UserID uint
+		Status string
+	}
+	if err := database.DB.Model(&Task{}).
+		Select("user_id, status").
+		Group("user_id, status").
+		Having("MAX(LENGTH(rank)) > ? OR MIN(LENGTH(rank)) = 0 OR COUNT(DISTINCT rank) < COUNT(*)", rankMaxLength).
+		Scan(&columns).Error; err != nil {
+		return 0, err
+	}
+
+	rebalanced := 0
+	for _, col := range columns {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return rebalanceColumn(tx, col.UserID, col.Status)
+		})
+		if err != nil {
+			log.Printf("[rank] user=%d status=%s rebalance failed: %v", col.UserID, col.Status, err)
+			continue
+		}
+		InvalidateSearchCache(col.UserID)
+		rebalanced++
+	}
+
+	return rebalanced, nil
+}
+
+func StartRankRebalancer(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for ; ; <-ticker.C {
+			n, err := RebalanceRanks()
+			if err != nil {
+				log.Println("[rank] rebalance failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("[rank] rebalanced %d column(s)", n)
+			}
+		}
+	}()
+}
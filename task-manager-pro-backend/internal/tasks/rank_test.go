@@ -0,0 +1,124 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+)
+
+func checkRankBetween(t *testing.T, lo, hi, got string) {
+	t.Helper()
+	if got == "" {
+		t.Fatalf("rankBetween(%q, %q) is empty", lo, hi)
+	}
+	if strings.HasSuffix(got, "0") {
+		t.Errorf("rankBetween(%q, %q) = %q ends with '0'", lo, hi, got)
+	}
+	if strings.Trim(got, rankAlphabet) != "" {
+		t.Errorf("rankBetween(%q, %q) = %q has characters outside the alphabet", lo, hi, got)
+	}
+	if got <= lo {
+		t.Errorf("rankBetween(%q, %q) = %q, want > %q", lo, hi, got, lo)
+	}
+	if hi != "" && got >= hi {
+		t.Errorf("rankBetween(%q, %q) = %q, want < %q", lo, hi, got, hi)
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		lo, hi string
+		want   string
+	}{
+		{"", "", "i"},
+		{"", "i", "9"},
+		{"i", "", "r"},
+		{"a", "c", "b"},
+		{"a", "b", "ai"},
+		{"az", "b", "azi"},
+		{"", "1", "0i"},
+		{"", "01", "00i"},
+		{"z", "", "zi"},
+		{"zz", "", "zzi"},
+		{"a1", "a2", "a1i"},
+		{"ay", "b1", "az"},
+	}
+
+	for _, tt := range tests {
+		got := rankBetween(tt.lo, tt.hi)
+		checkRankBetween(t, tt.lo, tt.hi, got)
+		if got != tt.want {
+			t.Errorf("rankBetween(%q, %q) = %q, want %q", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestRankBetweenRepeatedInserts(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  func(n int) int // posição de inserção numa coluna com n tasks
+	}{
+		{"front", func(n int) int { return 0 }},
+		{"back", func(n int) int { return n }},
+		{"after-first", func(n int) int { return min(1, n) }},
+		{"middle", func(n int) int { return n / 2 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var column []string
+			for i := 0; i < 300; i++ {
+				p := tt.pos(len(column))
+				lo, hi := "", ""
+				if p > 0 {
+					lo = column[p-1]
+				}
+				if p < len(column) {
+					hi = column[p]
+				}
+
+				r := rankBetween(lo, hi)
+				checkRankBetween(t, lo, hi, r)
+				if t.Failed() {
+					return
+				}
+				column = append(column[:p], append([]string{r}, column[p:]...)...)
+			}
+		})
+	}
+}
+
+func TestSpacedRanks(t *testing.T) {
+	tests := []struct {
+		n         int
+		wantWidth int
+	}{
+		// sempre um dígito a mais que o necessário, para sobrar espaço entre eles
+		{1, 2},
+		{10, 2},
+		{35, 2},
+		{100, 3},
+		{1295, 3},
+		{5000, 4},
+	}
+
+	for _, tt := range tests {
+		ranks := spacedRanks(tt.n)
+		if len(ranks) != tt.n {
+			t.Fatalf("spacedRanks(%d) returned %d ranks", tt.n, len(ranks))
+		}
+		for i, r := range ranks {
+			if r == "" || strings.HasSuffix(r, "0") {
+				t.Fatalf("spacedRanks(%d)[%d] = %q, want non-empty without trailing '0'", tt.n, i, r)
+			}
+			if len(r) > tt.wantWidth {
+				t.Fatalf("spacedRanks(%d)[%d] = %q, want at most %d characters", tt.n, i, r, tt.wantWidth)
+			}
+			if i > 0 && ranks[i-1] >= r {
+				t.Fatalf("spacedRanks(%d) not increasing at %d: %q >= %q", tt.n, i, ranks[i-1], r)
+			}
+		}
+		// sempre sobra espaço antes do primeiro e depois do último
+		checkRankBetween(t, "", ranks[0], rankBetween("", ranks[0]))
+		checkRankBetween(t, ranks[tt.n-1], "", rankBetween(ranks[tt.n-1], ""))
+	}
+}
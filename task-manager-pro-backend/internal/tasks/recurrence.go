@@ -0,0 +1,472 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// RecurrenceRule é um subconjunto do RRULE (RFC 5545):
+// FREQ=DAILY|WEEKLY|MONTHLY|YEARLY;INTERVAL=n;BYDAY=MO,WE;UNTIL=2025-12-31;COUNT=n
+type RecurrenceRule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Until    *time.Time
+	Count    int
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func invalidRecurrence(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRecurrence, fmt.Sprintf(format, args...))
+}
+
+func ParseRecurrence(raw string) (*RecurrenceRule, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(strings.ToUpper(raw), "RRULE:")
+	if raw == "" {
+		return nil, invalidRecurrence("empty rule")
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+
+	for _, part := range strings.Split(raw, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, invalidRecurrence("expected KEY=VALUE, got %q", part)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.Freq = value
+			default:
+				return nil, invalidRecurrence("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, invalidRecurrence("INTERVAL must be a positive integer")
+			}
+			rule.Interval = n
+		case "BYDAY":
+			rule.ByDay = nil
+			for _, code := range strings.Split(value, ",") {
+				wd, ok := weekdayCodes[strings.TrimSpace(code)]
+				if !ok {
+					return nil, invalidRecurrence("unknown BYDAY value %q", code)
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return nil, invalidRecurrence("UNTIL must be a date (YYYY-MM-DD, YYYYMMDD or RFC3339)")
+			}
+			rule.Until = &t
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, invalidRecurrence("COUNT must be a positive integer")
+			}
+			rule.Count = n
+		default:
+			return nil, invalidRecurrence("unsupported key %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, invalidRecurrence("FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != "WEEKLY" {
+		return nil, invalidRecurrence("BYDAY is only supported with FREQ=WEEKLY")
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	layouts := []string{time.RFC3339, "20060102T150405Z", "20060102", "2006-01-02"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			// datas sem hora valem até o fim do dia
+			if layout == "20060102" || layout == "2006-01-02" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date")
+}
+
+// String devolve a regra em formato canônico, que é o que fica salvo na task.
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			codes = append(codes, strings.ToUpper(wd.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next calcula a próxima ocorrência depois de base. Retorna false quando a
+// regra já terminou (UNTIL ultrapassado).
+func (r *RecurrenceRule) Next(base time.Time) (time.Time, bool) {
+	var next time.Time
+
+	switch r.Freq {
+	case "DAILY":
+		next = base.AddDate(0, 0, r.Interval)
+	case "WEEKLY":
+		if len(r.ByDay) == 0 {
+			next = base.AddDate(0, 0, 7*r.Interval)
+			break
+		}
+		next = r.nextByDay(base)
+	case "MONTHLY":
+		next = addMonthsClamped(base, r.Interval)
+	case "YEARLY":
+		next = addMonthsClamped(base, 12*r.Interval)
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+// nextByDay procura o próximo dia da semana permitido, pulando semanas
+// conforme o INTERVAL (semanas começam na segunda).
+func (r *RecurrenceRule) nextByDay(base time.Time) time.Time {
+	allowed := map[time.Weekday]bool{}
+	for _, wd := range r.ByDay {
+		allowed[wd] = true
+	}
+
+	baseWeek := startOfWeek(base)
+	for i := 1; i <= 7*r.Interval+7; i++ {
+		d := base.AddDate(0, 0, i)
+		weeks := daysBetween(baseWeek, startOfWeek(d)) / 7
+		if weeks%r.Interval == 0 && allowed[d.Weekday()] {
+			return d
+		}
+	}
+
+	return base.AddDate(0, 0, 7*r.Interval)
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // segunda = 0
+	y, m, d := t.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween conta dias de calendário; com horário de verão a diferença em
+// horas entre duas meias-noites não é múltipla de 24.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// addMonthsClamped evita o overflow do AddDate (31/01 + 1 mês = 28/02, não 03/03).
+func addMonthsClamped(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if d > lastDay {
+		d = lastDay
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// normalizeRecurrence valida a regra recebida na API e devolve a forma canônica.
+func normalizeRecurrence(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	rule, err := ParseRecurrence(raw)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// generateNextOccurrence cria a próxima task da série a partir de uma task
+// recorrente concluída. É idempotente: se NextOccurrenceID já está
+// preenchido, nada é feito.
+func generateNextOccurrence(tx *gorm.DB, task *Task) error {
+	if task.Recurrence == "" || task.NextOccurrenceID != nil || task.RecurrenceEnded {
+		return nil
+	}
+
+	rule, err := ParseRecurrence(task.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	root, err := lockSeriesRoot(tx, task)
+	if err != nil {
+		return err
+	}
+	seriesID := root.ID
+
+	// COUNT usa o contador da raiz: linhas apagadas definitivamente não
+	// devolvem ocorrências à série
+	occurrences := root.RecurrenceCount
+	if occurrences < 1 {
+		occurrences = 1 // a própria raiz
+	}
+	if rule.Count > 0 && occurrences >= rule.Count {
+		return endRecurrence(tx, task)
+	}
+
+	base := time.Now()
+	if task.DueDate != nil {
+		base = *task.DueDate
+	}
+	nextDue, ok := rule.Next(base)
+	if !ok {
+		return endRecurrence(tx, task)
+	}
+
+	if err := tx.Model(task).Association("Tags").Find(&task.Tags); err != nil {
+		return err
+	}
+
+	wf, err := LoadWorkflow(tx, task.UserID)
+	if err != nil {
+		return err
+	}
+	status := wf.InitialState()
+
+	rank, err := rankAtEnd(tx, task.UserID, status)
+	if err != nil {
+		return err
+	}
+
+	next := &Task{
+		UserID:      task.UserID,
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		SeriesID:    &seriesID,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		Status:      status,
+		Rank:        rank,
+		DueDate:     &nextDue,
+		Recurrence:  task.Recurrence,
+		Tags:        task.Tags,
+
+		EstimateMinutes:  task.EstimateMinutes,
+		StoryPoints:      task.StoryPoints,
+		RemainingMinutes: task.EstimateMinutes,
+	}
+	if err := tx.Create(next).Error; err != nil {
+		return err
+	}
+	if err := recordCreated(tx, task.UserID, next); err != nil {
+		return err
+	}
+	if err := recordTransition(tx, next, "", next.Status); err != nil {
+		return err
+	}
+
+	// o checklist é copiado "zerado" para a nova ocorrência
+	var items []ChecklistItem
+	if err := tx.Where("task_id = ?", task.ID).Order("position ASC, id ASC").Find(&items).Error; err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		copies := make([]ChecklistItem, len(items))
+		for i, it := range items {
+			copies[i] = ChecklistItem{TaskID: next.ID, Title: it.Title, Position: it.Position}
+		}
+		if err := tx.Create(&copies).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := copyReminders(tx, task, next); err != nil {
+		return err
+	}
+	if err := copyCustomFieldValues(tx, task, next); err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Model(&Task{}).
+		Where("id = ?", seriesID).
+		UpdateColumn("recurrence_count", occurrences+1).Error; err != nil {
+		return err
+	}
+
+	task.NextOccurrenceID = &next.ID
+	return tx.Model(&Task{}).
+		Where("id = ?", task.ID).
+		Update("next_occurrence_id", next.ID).Error
+}
+
+// lockSeriesRoot trava a raiz da série (mesmo na lixeira) para o contador de
+// ocorrências não ser lido por duas gerações ao mesmo tempo. Sem a raiz a
+// task passa a ser a raiz.
+func lockSeriesRoot(tx *gorm.DB, task *Task) (*Task, error) {
+	if task.SeriesID == nil {
+		return task, nil
+	}
+
+	var root Task
+	err := tx.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", *task.SeriesID).
+		First(&root).Error
+	if err == nil {
+		return &root, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	task.SeriesID = nil
+	if err := tx.Model(&Task{}).Where("id = ?", task.ID).Update("series_id", nil).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// backfillRecurrenceCounts preenche o contador das séries criadas antes
+// dele com o número de ocorrências que ainda existem.
+func backfillRecurrenceCounts(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE tasks AS root
+		SET recurrence_count = (
+			SELECT COUNT(*) FROM tasks t WHERE t.id = root.id OR t.series_id = root.id
+		)
+		WHERE root.series_id IS NULL AND root.recurrence_count = 0
+			AND EXISTS (SELECT 1 FROM tasks t WHERE t.series_id = root.id)`).Error
+}
+
+// endRecurrence marca a série como encerrada (COUNT/UNTIL atingidos) para o
+// gerador em background não tentar de novo.
+func endRecurrence(tx *gorm.DB, task *Task) error {
+	task.RecurrenceEnded = true
+	return tx.Model(&Task{}).
+		Where("id = ?", task.ID).
+		Update("recurrence_ended", true).Error
+}
+
+// Limites do gerador em background: tasks por execução e falhas seguidas
+// antes de a task deixar de ser tentada (até a regra ser editada).
+const (
+	recurrenceBatchSize   = 100
+	maxRecurrenceAttempts = 5
+)
+
+// GeneratePendingOccurrences é o gerador em background: cria a próxima
+// ocorrência de tasks recorrentes concluídas que ainda não têm uma (ex: se
+// a geração no UpdateTask falhou). Cada task é travada com SKIP LOCKED na
+// própria transação, então várias instâncias (ou um UpdateTask em curso)
+// não geram a mesma ocorrência duas vezes.
+func GeneratePendingOccurrences() (int, error) {
+	generated := 0
+	var lastID uint
+
+	for n := 0; n < recurrenceBatchSize; n++ {
+		var pending []Task
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id > ? AND recurrence <> '' AND status = ? AND next_occurrence_id IS NULL AND NOT recurrence_ended AND recurrence_errors < ?",
+					lastID, StatusDone, maxRecurrenceAttempts).
+				Order("id ASC").
+				Limit(1).
+				Find(&pending).Error; err != nil {
+				return err
+			}
+			if len(pending) == 0 {
+				return nil
+			}
+			return generateNextOccurrence(tx, &pending[0])
+		})
+		if len(pending) == 0 {
+			if err != nil {
+				return generated, err
+			}
+			break
+		}
+
+		task := &pending[0]
+		lastID = task.ID
+		if err != nil {
+			log.Printf("[recurrence] task=%d attempt=%d err=%v", task.ID, task.RecurrenceErrors+1, err)
+			if err := database.DB.Model(&Task{}).
+				Where("id = ?", task.ID).
+				UpdateColumn("recurrence_errors", gorm.Expr("recurrence_errors + 1")).Error; err != nil {
+				return generated, err
+			}
+			continue
+		}
+		if task.NextOccurrenceID != nil {
+			InvalidateSearchCache(task.UserID)
+			generated++
+		}
+	}
+
+	return generated, nil
+}
+
+func StartRecurrenceGenerator(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			n, err := GeneratePendingOccurrences()
+			if err != nil {
+				log.Println("[recurrence] generator failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("[recurrence] generated %d occurrence(s)", n)
+			}
+		}
+	}()
+}
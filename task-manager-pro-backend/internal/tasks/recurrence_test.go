@@ -0,0 +1,118 @@
+package tasks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string // forma canônica; vazio = erro esperado
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"rrule:freq=weekly;byday=mo,we", "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{" FREQ=MONTHLY ; INTERVAL=3 ; COUNT=5 ;", "FREQ=MONTHLY;INTERVAL=3;COUNT=5"},
+		{"FREQ=DAILY;UNTIL=2025-12-31", "FREQ=DAILY;UNTIL=20251231T235959Z"},
+		{"FREQ=DAILY;UNTIL=20251231", "FREQ=DAILY;UNTIL=20251231T235959Z"},
+		{"FREQ=YEARLY;UNTIL=2030-01-02T10:00:00Z", "FREQ=YEARLY;UNTIL=20300102T100000Z"},
+
+		{"", ""},
+		{"RRULE:", ""},
+		{"FREQ", ""},
+		{"FREQ=HOURLY", ""},
+		{"INTERVAL=2", ""},
+		{"FREQ=DAILY;INTERVAL=0", ""},
+		{"FREQ=DAILY;COUNT=-1", ""},
+		{"FREQ=DAILY;BYDAY=MO", ""},
+		{"FREQ=WEEKLY;BYDAY=XX", ""},
+		{"FREQ=DAILY;UNTIL=tomorrow", ""},
+		{"FREQ=DAILY;BYMONTH=1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.raw)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidRecurrence) {
+					t.Fatalf("ParseRecurrence(%q) error = %v, want ErrInvalidRecurrence", tt.raw, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error: %v", tt.raw, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("ParseRecurrence(%q).String() = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	utc := func(y int, m time.Month, d, h int) time.Time {
+		return time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		rule   string
+		base   time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{"daily", "FREQ=DAILY", utc(2025, 3, 3, 9), utc(2025, 3, 4, 9), true},
+		{"daily interval", "FREQ=DAILY;INTERVAL=3", utc(2025, 3, 30, 9), utc(2025, 4, 2, 9), true},
+		{"weekly", "FREQ=WEEKLY", utc(2025, 3, 3, 9), utc(2025, 3, 10, 9), true},
+		{"weekly interval", "FREQ=WEEKLY;INTERVAL=2", utc(2025, 3, 3, 9), utc(2025, 3, 17, 9), true},
+		// 2025-03-03 é segunda
+		{"byday same week", "FREQ=WEEKLY;BYDAY=MO,WE", utc(2025, 3, 3, 9), utc(2025, 3, 5, 9), true},
+		{"byday next week", "FREQ=WEEKLY;BYDAY=MO,WE", utc(2025, 3, 5, 9), utc(2025, 3, 10, 9), true},
+		{"byday sunday ends week", "FREQ=WEEKLY;BYDAY=SU", utc(2025, 3, 3, 9), utc(2025, 3, 9, 9), true},
+		{"byday interval skips week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", utc(2025, 3, 5, 9), utc(2025, 3, 17, 9), true},
+		{"byday interval from friday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", utc(2025, 3, 7, 9), utc(2025, 3, 17, 9), true},
+		{"monthly", "FREQ=MONTHLY", utc(2025, 3, 15, 9), utc(2025, 4, 15, 9), true},
+		{"monthly clamps", "FREQ=MONTHLY", utc(2025, 1, 31, 9), utc(2025, 2, 28, 9), true},
+		{"monthly clamps leap", "FREQ=MONTHLY", utc(2024, 1, 31, 9), utc(2024, 2, 29, 9), true},
+		{"monthly across year", "FREQ=MONTHLY;INTERVAL=2", utc(2025, 12, 10, 9), utc(2026, 2, 10, 9), true},
+		{"yearly leap day", "FREQ=YEARLY", utc(2024, 2, 29, 9), utc(2025, 2, 28, 9), true},
+		{"until inside", "FREQ=DAILY;UNTIL=2025-03-04", utc(2025, 3, 3, 9), utc(2025, 3, 4, 9), true},
+		{"until passed", "FREQ=DAILY;UNTIL=2025-03-04", utc(2025, 3, 4, 9), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error: %v", tt.rule, err)
+			}
+			got, ok := rule.Next(tt.base)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, %v; want %s, %v", tt.base, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// A semana do início do horário de verão tem 167 horas; a contagem de
+// semanas do INTERVAL não pode depender disso.
+func TestRecurrenceNextAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data not available:", err)
+	}
+
+	rule, err := ParseRecurrence("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// quarta antes do domingo 2025-03-09 (início do horário de verão)
+	base := time.Date(2025, 3, 5, 9, 0, 0, 0, loc)
+	want := time.Date(2025, 3, 17, 9, 0, 0, 0, loc)
+	if got, ok := rule.Next(base); !ok || !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, %v; want %s", base, got, ok, want)
+	}
+}
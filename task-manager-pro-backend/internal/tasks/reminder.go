@@ -0,0 +1,234 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidReminder = errors.New("invalid reminder")
+
+// reminderFireAt calcula quando o lembrete dispara; nil enquanto a task não
+// tiver due_date (lembretes por offset).
+func reminderFireAt(r *TaskReminder, dueDate *time.Time) *time.Time {
+	if r.RemindAt != nil {
+		t := *r.RemindAt
+		return &t
+	}
+	if r.OffsetMinutes != nil && dueDate != nil {
+		t := dueDate.Add(-time.Duration(*r.OffsetMinutes) * time.Minute)
+		return &t
+	}
+	return nil
+}
+
+func ListReminders(userID uint, taskID uint) ([]TaskReminder, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	reminders := []TaskReminder{}
+	if err := database.DB.
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("fire_at ASC NULLS LAST, id ASC").
+		Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+func CreateReminder(userID uint, taskID uint, input ReminderInput) (*TaskReminder, error) {
+	if (input.RemindAt == nil) == (input.OffsetMinutes == nil) {
+		return nil, fmt.Errorf("%w: set either remind_at or offset_minutes", ErrInvalidReminder)
+	}
+	if input.OffsetMinutes != nil && *input.OffsetMinutes < 0 {
+		return nil, fmt.Errorf("%w: offset_minutes must not be negative", ErrInvalidReminder)
+	}
+	if input.RemindAt != nil && !input.RemindAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: remind_at must be in the future", ErrInvalidReminder)
+	}
+
+	task, err := findUserTask(database.DB, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder := &TaskReminder{
+		TaskID:        taskID,
+		UserID:        userID,
+		RemindAt:      input.RemindAt,
+		OffsetMinutes: input.OffsetMinutes,
+	}
+	reminder.FireAt = reminderFireAt(reminder, task.DueDate)
+
+	if err := database.DB.Create(reminder).Error; err != nil {
+		return nil, err
+	}
+
+	return reminder, nil
+}
+
+func DeleteReminder(userID uint, taskID uint, reminderID uint) error {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return err
+	}
+
+	res := database.DB.
+		Where("id = ? AND task_id = ? AND user_id = ?", reminderID, taskID, userID).
+		Delete(&TaskReminder{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// rescheduleReminders recalcula os lembretes por offset ainda não disparados
+// quando o due_date da task muda.
+func rescheduleReminders(tx *gorm.DB, task *Task) error {
+	var pending []TaskReminder
+	if err := tx.
+		Where("task_id = ? AND fired_at IS NULL AND offset_minutes IS NOT NULL", task.ID).
+		Find(&pending).Error; err != nil {
+		return err
+	}
+
+	for i := range pending {
+		if err := tx.Model(&pending[i]).
+			Update("fire_at", reminderFireAt(&pending[i], task.DueDate)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyReminders leva os lembretes por offset para a próxima ocorrência de
+// uma task recorrente (horários absolutos não fazem sentido na série).
+func copyReminders(tx *gorm.DB, from *Task, to *Task) error {
+	var offsets []TaskReminder
+	if err := tx.
+		Where("task_id = ? AND offset_minutes IS NOT NULL", from.ID).
+		Find(&offsets).Error; err != nil {
+		return err
+	}
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	copies := make([]TaskReminder, len(offsets))
+	for i, r := range offsets {
+		copies[i] = TaskReminder{TaskID: to.ID, UserID: to.UserID, OffsetMinutes: r.OffsetMinutes}
+		copies[i].FireAt = reminderFireAt(&copies[i], to.DueDate)
+	}
+
+	return tx.Create(&copies).Error
+}
+
+// FireDueReminders dispara os lembretes vencidos. Marcar fired_at e gravar a
+// notificação no outbox acontecem na mesma transação, então cada lembrete
+// dispara uma única vez mesmo com restart ou mais de uma instância rodando.
+func FireDueReminders(now time.Time) (int, error) {
+	fired := 0
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// tasks na lixeira ficam de fora: o lembrete volta a valer no restore
+		var due []TaskReminder
+		if err := tx.
+			Clauses(clause.Locking{
+				Strength: "UPDATE",
+				Table:    clause.Table{Name: "task_reminders"},
+				Options:  "SKIP LOCKED",
+			}).
+			Select("task_reminders.*").
+			Joins("JOIN tasks ON tasks.id = task_reminders.task_id AND tasks.deleted_at IS NULL").
+			Where("task_reminders.fired_at IS NULL AND task_reminders.fire_at <= ?", now).
+			Order("task_reminders.fire_at ASC").
+			Limit(100).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		taskIDs := make([]uint, len(due))
+		for i, r := range due {
+			taskIDs[i] = r.TaskID
+		}
+		var owners []Task
+		if err := tx.Where("id IN ?", taskIDs).Find(&owners).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*Task, len(owners))
+		for i := range owners {
+			byID[owners[i].ID] = &owners[i]
+		}
+
+		for i := range due {
+			r := &due[i]
+			task := byID[r.TaskID]
+
+			// task já concluída: o lembrete é consumido sem notificar
+			if task != nil && task.Status != StatusDone {
+				if err := notifications.Enqueue(tx, reminderNotification(task, r)); err != nil {
+					return err
+				}
+				fired++
+			}
+
+			if err := tx.Model(r).Update("fired_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return fired, err
+}
+
+func reminderNotification(task *Task, r *TaskReminder) *notifications.Notification {
+	body := fmt.Sprintf("Reminder for task %q", task.Title)
+	if task.DueDate != nil {
+		body = fmt.Sprintf("Task %q is due at %s", task.Title, task.DueDate.UTC().Format(time.RFC3339))
+	}
+
+	taskID := task.ID
+	reminderID := r.ID
+	return &notifications.Notification{
+		UserID:     task.UserID,
+		TaskID:     &taskID,
+		ReminderID: &reminderID,
+		Kind:       notifications.KindReminder,
+		Title:      task.Title,
+		Body:       body,
+	}
+}
+
+func StartReminderScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for ; ; <-ticker.C {
+			n, err := FireDueReminders(time.Now())
+			if err != nil {
+				log.Println("[reminders] scheduler failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("[reminders] fired %d reminder(s)", n)
+			}
+		}
+	}()
+}
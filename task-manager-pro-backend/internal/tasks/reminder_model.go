@@ -0,0 +1,17 @@
+package tasks
+
+import "time"
+
+// TaskReminder dispara uma notificação num horário absoluto (remind_at) ou
+// offset_minutes antes do due_date da task.
+type TaskReminder struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	TaskID        uint       `json:"task_id" gorm:"index"`
+	UserID        uint       `json:"user_id" gorm:"index"`
+	RemindAt      *time.Time `json:"remind_at,omitempty"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty"`
+	FireAt        *time.Time `json:"fire_at" gorm:"index"` // nil = offset numa task sem due_date
+	FiredAt       *time.Time `json:"fired_at" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -2,63 +2,115 @@ package tasks
 
 import (
 	"strings"
+	"time"
 
 	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func createOrGetTags(userID uint, names []string) ([]Tag, error) {
+func createOrGetTags(db *gorm.DB, userID uint, names []string) ([]Tag, error) {
 	if len(names) == 0 {
 		return []Tag{}, nil
 	}
 
 	var tags []Tag
+	seen := map[string]bool{}
 	for _, n := range names {
-		name := strings.TrimSpace(n)
-		if name == "" {
+		name := normalizeTagPath(n)
+		if name == "" || seen[name] {
 			continue
 		}
+		seen[name] = true
 
-		var tag Tag
-		err := database.DB.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error
-
+		// "client/acme" cria também "client", se ainda não existir
+		tag, err := ensureTagPath(db, userID, name)
 		if err != nil {
-			if err == gorm.ErrRecordNotFound {
-				tag = Tag{
-					UserID: userID,
-					Name:   name,
-				}
-				if err := database.DB.Create(&tag).Error; err != nil {
-					return nil, err
-				}
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
-		tags = append(tags, tag)
+		tags = append(tags, *tag)
 	}
 
 	return tags, nil
 }
 
 func CreateTask(userID uint, input CreateTaskInput) (*Task, error) {
-	tags, err := createOrGetTags(userID, input.Tags)
+	return createTask(userID, nil, input)
+}
+
+func createTask(userID uint, parentID *uint, input CreateTaskInput) (*Task, error) {
+	recurrence, err := normalizeRecurrence(input.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	priority, err := normalizePriority(input.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	// Na criação não existe estado anterior: qualquer estado do workflow vale
+	wf, err := LoadWorkflow(database.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := wf.ValidateStatus(input.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := validateTaskProject(database.DB, userID, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := createOrGetTags(database.DB, userID, input.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// tasks novas entram no fim da coluna
+	rank, err := rankAtEnd(database.DB, userID, status)
 	if err != nil {
 		return nil, err
 	}
 
 	task := &Task{
 		UserID:      userID,
+		ParentID:    parentID,
+		ProjectID:   projectID,
 		Title:       input.Title,
 		Description: input.Description,
-		Priority:    strings.ToUpper(input.Priority),
-		Status:      strings.ToUpper(input.Status),
+		Priority:    priority,
+		Status:      status,
+		Rank:        rank,
 		DueDate:     input.DueDate,
+		Recurrence:  recurrence,
 		Tags:        tags,
+
+		EstimateMinutes:  input.EstimateMinutes,
+		StoryPoints:      input.StoryPoints,
+		RemainingMinutes: input.RemainingMinutes,
+	}
+	if task.RemainingMinutes == nil && task.EstimateMinutes != nil {
+		remaining := *task.EstimateMinutes
+		task.RemainingMinutes = &remaining
 	}
 
-	if err := database.DB.Create(task).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		if err := setCustomFieldValues(tx, userID, task.ID, input.CustomFields, true); err != nil {
+			return err
+		}
+		if err := recordCreated(tx, userID, task); err != nil {
+			return err
+		}
+		return recordTransition(tx, task, "", task.Status)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -66,11 +118,38 @@ func CreateTask(userID uint, input CreateTaskInput) (*Task, error) {
 }
 
 func UpdateTask(userID uint, id uint, input UpdateTaskInput) (*Task, error) {
+	var task *Task
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		task, err = updateTask(tx, userID, id, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enrichTask(task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// updateTask aplica o UpdateTaskInput dentro de uma transação já aberta
+// (usado também pelas operações em lote).
+func updateTask(tx *gorm.DB, userID uint, id uint, input UpdateTaskInput) (*Task, error) {
+	// a linha fica travada até o fim da transação: o gerador de recorrência
+	// (GeneratePendingOccurrences) não processa a task ao mesmo tempo
 	var task Task
-	if err := database.DB.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", id, userID).
 		First(&task).Error; err != nil {
 		return nil, err
 	}
+	if err := tx.Model(&task).Association("Tags").Find(&task.Tags); err != nil {
+		return nil, err
+	}
+	before := snapshotTask(&task)
 
 	if input.Title != nil {
 		task.Title = *input.Title
@@ -79,109 +158,326 @@ func UpdateTask(userID uint, id uint, input UpdateTaskInput) (*Task, error) {
 		task.Description = *input.Description
 	}
 	if input.Priority != nil {
-		task.Priority = strings.ToUpper(*input.Priority)
+		priority, err := normalizePriority(*input.Priority)
+		if err != nil {
+			return nil, err
+		}
+		task.Priority = priority
 	}
+	previousStatus := task.Status
+	wasDone := task.Status == "DONE"
 	if input.Status != nil {
-		task.Status = strings.ToUpper(*input.Status)
+		wf, err := LoadWorkflow(tx, userID)
+		if err != nil {
+			return nil, err
+		}
+		status, err := wf.ValidateStatus(*input.Status)
+		if err != nil {
+			return nil, err
+		}
+		if err := wf.ValidateTransition(previousStatus, status); err != nil {
+			return nil, err
+		}
+		if status != previousStatus {
+			// mudou de coluna: vai para o fim da nova
+			rank, err := rankAtEnd(tx, userID, status)
+			if err != nil {
+				return nil, err
+			}
+			task.Rank = rank
+		}
+		task.Status = status
 	}
 	if input.DueDate != nil {
 		task.DueDate = input.DueDate
 	}
+	if input.Recurrence != nil {
+		recurrence, err := normalizeRecurrence(*input.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+		task.Recurrence = recurrence
+		task.RecurrenceEnded = false
+		task.RecurrenceErrors = 0
+	}
+	if input.ProjectID != nil {
+		projectID, err := validateTaskProject(tx, userID, input.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		task.ProjectID = projectID
+	}
+	if input.StoryPoints != nil {
+		task.StoryPoints = input.StoryPoints
+	}
+	if input.EstimateMinutes != nil {
+		// estimativa nova sem remaining explícito: o restante acompanha
+		if input.RemainingMinutes == nil && (task.RemainingMinutes == nil || task.EstimateMinutes == nil ||
+			*task.RemainingMinutes == *task.EstimateMinutes) {
+			remaining := *input.EstimateMinutes
+			task.RemainingMinutes = &remaining
+		}
+		task.EstimateMinutes = input.EstimateMinutes
+	}
+	if input.RemainingMinutes != nil {
+		task.RemainingMinutes = input.RemainingMinutes
+	}
 	if input.Tags != nil {
-		tags, err := createOrGetTags(userID, *input.Tags)
+		tags, err := createOrGetTags(tx, userID, *input.Tags)
 		if err != nil {
 			return nil, err
 		}
 		task.Tags = tags
 	}
 
-	if err := database.DB.Save(&task).Error; err != nil {
+	// Uma task bloqueada não pode ser concluída
+	if !wasDone && task.Status == "DONE" {
+		blocked, err := isBlocked(tx, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrTaskBlocked
+		}
+
+		// task concluída não tem mais trabalho restante
+		if task.RemainingMinutes != nil && input.RemainingMinutes == nil {
+			zero := 0
+			task.RemainingMinutes = &zero
+		}
+	}
+
+	if err := tx.Save(&task).Error; err != nil {
 		return nil, err
 	}
 
+	// Save só adiciona associações; Replace remove as tags que saíram
+	if input.Tags != nil {
+		if err := tx.Model(&task).Association("Tags").Replace(task.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.DueDate != nil {
+		if err := rescheduleReminders(tx, &task); err != nil {
+			return nil, err
+		}
+	}
+	if err := setCustomFieldValues(tx, userID, task.ID, input.CustomFields, false); err != nil {
+		return nil, err
+	}
+
+	if err := recordChanges(tx, userID, &task, before, snapshotTask(&task)); err != nil {
+		return nil, err
+	}
+	if err := recordTransition(tx, &task, previousStatus, task.Status); err != nil {
+		return nil, err
+	}
+
+	if !wasDone && task.Status == "DONE" {
+		// Concluir a task pai conclui também subtasks e checklist
+		if err := cascadeDone(tx, userID, task.ID); err != nil {
+			return nil, err
+		}
+
+		// Task recorrente: gera a próxima ocorrência da série
+		if err := generateNextOccurrence(tx, &task); err != nil {
+			return nil, err
+		}
+	}
+
 	return &task, nil
 }
 
+// DeleteTask move a task (e suas subtasks) para a lixeira. Tags, checklist
+// e dependências continuam ligadas para que o restore seja completo.
 func DeleteTask(userID uint, taskID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteTask(tx, userID, taskID)
+	})
+}
+
+func deleteTask(tx *gorm.DB, userID uint, taskID uint) error {
 	var task Task
 
 	// 1) Buscar a task do usuário
-	if err := database.DB.
+	if err := tx.
 		Where("id = ? AND user_id = ?", taskID, userID).
 		First(&task).Error; err != nil {
 		return err
 	}
 
-	// 2) Limpar as associações na tabela task_tags
-	if err := database.DB.
-		Model(&task).
-		Association("Tags").
-		Clear(); err != nil {
-		return err
-	}
-
-	// 3) Deletar a task
-	if err := database.DB.Delete(&task).Error; err != nil {
-		return err
-	}
-
-	return nil
+	// 2) Soft delete da task e das subtasks
+	return softDeleteTree(tx, userID, &task)
 }
 
 func GetTaskByID(userID uint, id uint) (*Task, error) {
 	var task Task
-	err := database.DB.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&task).Error
+	err := database.DB.
+		Preload("Tags").
+		Preload("Checklist", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		}).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&task).Error
 	if err != nil {
 		return nil, err
 	}
 
+	if err := enrichTask(&task); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
 
-func ListTasks(userID uint, filter TaskFilter) ([]Task, error) {
+// ListTasks lista as tasks do usuário com filtros, ordenação e paginação
+// por cursor (keyset), que não degrada em páginas profundas.
+func ListTasks(userID uint, filter TaskFilter) (*TaskPage, error) {
+	if err := prepareFilter(userID, &filter); err != nil {
+		return nil, err
+	}
+
+	db := taskFilterQuery(userID, filter)
+
+	page := &TaskPage{Items: []Task{}}
+	if filter.WithTotal {
+		var total int64
+		if err := db.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	query := db.Preload("Tags").Order(orderClause(filter))
+	if filter.Cursor != "" {
+		var err error
+		if query, err = applyCursor(query, filter); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Limit > 0 {
+		// busca um a mais para saber se existe próxima página
+		query = query.Limit(filter.Limit + 1)
+	}
+
+	if err := query.Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+
+	hasMore := filter.Limit > 0 && len(page.Items) > filter.Limit
+	if hasMore {
+		page.Items = page.Items[:filter.Limit]
+	}
+
+	if err := enrichTasks(page.Items); err != nil {
+		return nil, err
+	}
+
+	// o cursor usa campos calculados (custom fields), então vem depois
+	if hasMore {
+		page.NextCursor = encodeCursor(&page.Items[len(page.Items)-1], filter)
+	}
+
+	return page, nil
+}
+
+// taskFilterQuery monta a query base (sem ordenação/paginação) a partir dos
+// filtros. Tags são filtradas com EXISTS para não duplicar linhas.
+func taskFilterQuery(userID uint, filter TaskFilter) *gorm.DB {
 	db := database.DB.
 		Model(&Task{}).
-		Preload("Tags").
 		Where("tasks.user_id = ?", userID)
 
 	// filtros simples
 	if filter.Status != "" {
-		db = db.Where("tasks.status = ?", strings.ToUpper(filter.Status))
+		db = db.Where("tasks.status = ?", normalizeStatus(filter.Status))
 	}
 	if filter.Priority != "" {
 		db = db.Where("tasks.priority = ?", strings.ToUpper(filter.Priority))
 	}
+	if filter.ProjectID != nil {
+		db = db.Where("tasks.project_id = ?", *filter.ProjectID)
+	}
+
+	// campos customizados (já resolvidos em prepareFilter)
+	for _, f := range filter.CustomFields {
+		if f.field == nil {
+			continue
+		}
+		cond, args := customFieldCondition(f)
+		db = db.Where(cond, args...)
+	}
 
 	// 🔍 Query: busca em title, description E tags.name
 	if filter.Query != "" {
 		q := "%" + strings.TrimSpace(filter.Query) + "%"
 
-		db = db.
-			Joins("LEFT JOIN task_tags ON task_tags.task_id = tasks.id").
-			Joins("LEFT JOIN tags ON tags.id = task_tags.tag_id").
-			Where(`
+		db = db.Where(`
       (
         tasks.title ILIKE ?
         OR tasks.description ILIKE ?
-        OR tags.name ILIKE ?
+        OR EXISTS (
+          SELECT 1 FROM task_tags tt
+          JOIN tags t ON t.id = tt.tag_id
+          WHERE tt.task_id = tasks.id AND t.name ILIKE ?
+        )
       )
-    `, q, q, q).
-			Group("tasks.id")
+    `, q, q, q)
+	}
+
+	// linguagem de busca (?query=), já compilada em prepareFilter
+	if filter.expr != nil {
+		db = db.Where(filter.expr.sql, filter.expr.args...)
 	}
 
-	// filtro por tag exata (se você quiser continuar suportando)
+	// filtro por tag exata, ou pela subárvore com "client/*"
 	if filter.Tags != "" {
-		db = db.
-			Joins("JOIN task_tags tt ON tt.task_id = tasks.id").
-			Joins("JOIN tags t ON t.id = tt.tag_id").
-			Where("t.name = ?", strings.TrimSpace(filter.Tags)).
-			Distinct("tasks.id")
+		cond, args := tagFilterCondition(filter.Tags)
+		db = db.Where(`EXISTS (
+      SELECT 1 FROM task_tags tt
+      JOIN tags t ON t.id = tt.tag_id
+      WHERE tt.task_id = tasks.id AND `+cond+`
+    )`, args...)
 	}
 
-	var tasks []Task
-	if err := db.Order("tasks.created_at DESC").Find(&tasks).Error; err != nil {
-		return nil, err
+	// filtros de data
+	if filter.DueBefore != nil {
+		db = db.Where("tasks.due_date < ?", *filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		db = db.Where("tasks.due_date > ?", *filter.DueAfter)
+	}
+	if filter.Overdue {
+		db = db.Where("tasks.due_date < ? AND tasks.status <> ?", time.Now(), StatusDone)
+	}
+	if filter.CreatedSince != nil {
+		db = db.Where("tasks.created_at >= ?", *filter.CreatedSince)
 	}
 
-	return tasks, nil
+	return db.Session(&gorm.Session{})
+}
+
+// enrichTasks preenche os campos calculados (progresso, bloqueio,
+// comentários, campos customizados) das tasks.
+func enrichTasks(tasks []Task) error {
+	if err := attachProgress(tasks); err != nil {
+		return err
+	}
+	if err := attachBlocked(tasks); err != nil {
+		return err
+	}
+	if err := attachCommentCounts(tasks); err != nil {
+		return err
+	}
+	return attachCustomFields(tasks)
+}
+
+func enrichTask(task *Task) error {
+	list := []Task{*task}
+	if err := enrichTasks(list); err != nil {
+		return err
+	}
+	*task = list[0]
+	return nil
 }
@@ -15,41 +15,113 @@ import (
 // Context global do Redis
 var redisCtx = context.Background()
 
+// searchCacheTTL é o tempo de vida dos resultados em cache; sobrescrito pela
+// config em SetSearchCacheTTL.
+var searchCacheTTL = 30 * time.Second
+
+func SetSearchCacheTTL(ttl time.Duration) {
+	if ttl > 0 {
+		searchCacheTTL = ttl
+	}
+}
+
 // Gera hash da query para criar chave única por usuário + busca
 func hashQuery(q string) string {
 	h := sha1.Sum([]byte(q))
 	return hex.EncodeToString(h[:])
 }
 
+// SearchOptions são filtros opcionais da busca; entram na chave do cache.
+type SearchOptions struct {
+	ProjectID *uint
+	Fuzzy     bool // busca direto por similaridade (pg_trgm)
+}
+
+func (o SearchOptions) cacheSuffix() string {
+	suffix := ""
+	if o.ProjectID != nil {
+		suffix += "\x00project=" + strconv.Itoa(int(*o.ProjectID))
+	}
+	if o.Fuzzy {
+		suffix += "\x00fuzzy"
+	}
+	return suffix
+}
+
+// SearchResult é a resposta da busca. Fuzzy indica que os itens vieram da
+// busca por similaridade (fuzzy=true ou nenhum resultado exato); nesse caso
+// DidYouMean traz a query corrigida, se houver.
+type SearchResult struct {
+	Items      []Task   `json:"items"`
+	Fuzzy      bool     `json:"fuzzy"`
+	DidYouMean []string `json:"did_you_mean,omitempty"`
+}
+
+// runSearch faz a busca full-text e cai para a busca por similaridade
+// quando ela não encontra nada.
+func runSearch(userID uint, query string, opts SearchOptions) (*SearchResult, error) {
+	if !opts.Fuzzy {
+		tasks, err := fullTextSearch(userID, query, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(tasks) > 0 {
+			return &SearchResult{Items: tasks}, nil
+		}
+	}
+
+	tasks, err := fuzzySearch(userID, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	suggestions, err := didYouMean(userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchResult{Items: tasks, Fuzzy: true, DidYouMean: suggestions}, nil
+}
+
 // ---------------------------------------------------------------------------
 // SearchTasks — Busca com Cache + Histórico
 // ---------------------------------------------------------------------------
-func SearchTasks(userID uint, query string) ([]Task, error) {
+func SearchTasks(userID uint, query string, opts SearchOptions) (*SearchResult, error) {
 	// fallback caso Redis não esteja configurado
 	if redisClient == nil || redisClient.Client == nil {
 		fmt.Println("Redis não configurado. Usando busca direta no Postgres.")
-		filter := TaskFilter{Query: query}
-		return ListTasks(userID, filter)
+		return runSearch(userID, query, opts)
 	}
 
 	userIDStr := strconv.Itoa(int(userID))
-	queryHash := hashQuery(query)
+	queryHash := hashQuery(query + opts.cacheSuffix())
 
-	cacheKey := "tmpro:search:result:" + userIDStr + ":" + queryHash
-	historyKey := "tmpro:search:history:" + userIDStr
+	// A chave inclui a versão do namespace de busca do usuário: qualquer
+	// escrita muda a versão e os resultados antigos deixam de ser lidos.
+	// Sem a versão (erro no Redis) a busca vai direto ao banco.
+	version, err := searchVersion(userID)
+	if err != nil {
+		fmt.Println("Erro ao ler versão do cache de busca:", err)
+		result, err := runSearch(userID, query, opts)
+		if err != nil {
+			return nil, err
+		}
+		_ = pushSearchHistory(userID, query)
+		return result, nil
+	}
+	cacheKey := "tmpro:search:result:" + userIDStr + ":v" + version + ":" + queryHash
 
 	// -----------------------------------------------------------------------
 	// 1. Tenta pegar a busca do cache
 	// -----------------------------------------------------------------------
 	cached, err := redisClient.Client.Get(redisCtx, cacheKey).Result()
 	if err == nil && cached != "" {
-		var tasks []Task
+		var result SearchResult
 
-		if json.Unmarshal([]byte(cached), &tasks) == nil {
+		if json.Unmarshal([]byte(cached), &result) == nil {
 			// Atualiza histórico mesmo quando pega do cache
-			_ = pushSearchHistory(historyKey, query)
+			_ = pushSearchHistory(userID, query)
 			fmt.Println("Resultado retornado do CACHE!")
-			return tasks, nil
+			return &result, nil
 		}
 	} else if err != nil && err != redis.Nil {
 		// erro inesperado do Redis (não derruba o sistema)
@@ -57,57 +129,61 @@ func SearchTasks(userID uint, query string) ([]Task, error) {
 	}
 
 	// -----------------------------------------------------------------------
-	// 2. Busca no Postgres usando o ListTasks (já existente)
+	// 2. Busca no Postgres (full-text por relevância, fuzzy como fallback)
 	// -----------------------------------------------------------------------
-	filter := TaskFilter{Query: query}
-	tasks, err := ListTasks(userID, filter)
+	result, err := runSearch(userID, query, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	// -----------------------------------------------------------------------
-	// 3. Salva no cache (TTL: 30s)
+	// 3. Salva no cache (TTL: searchCacheTTL)
 	// -----------------------------------------------------------------------
-	jsonData, _ := json.Marshal(tasks)
+	jsonData, _ := json.Marshal(result)
 
-	if err := redisClient.Client.Set(redisCtx, cacheKey, jsonData, 30*time.Second).Err(); err != nil {
+	if err := redisClient.Client.Set(redisCtx, cacheKey, jsonData, searchCacheTTL).Err(); err != nil {
 		fmt.Println("Erro ao salvar no Redis:", err)
 	}
 
 	// -----------------------------------------------------------------------
-	// 4. Atualiza o histórico (últimas 10 buscas)
+	// 4. Atualiza o histórico (últimas 10 buscas, ver search_history.go)
 	// -----------------------------------------------------------------------
-	if err := pushSearchHistory(historyKey, query); err != nil {
+	if err := pushSearchHistory(userID, query); err != nil {
 		fmt.Println("Erro ao atualizar histórico:", err)
 	}
 
-	return tasks, nil
+	return result, nil
 }
 
 // ---------------------------------------------------------------------------
-// Função auxiliar para armazenar histórico das últimas 10 buscas
+// Versionamento do cache de busca
 // ---------------------------------------------------------------------------
-func pushSearchHistory(historyKey, query string) error {
-	pipe := redisClient.Client.TxPipeline()
-
-	pipe.LPush(redisCtx, historyKey, query)
-	pipe.LTrim(redisCtx, historyKey, 0, 9) // mantém apenas as últimas 10
+func searchVersionKey(userID uint) string {
+	return "tmpro:search:version:" + strconv.Itoa(int(userID))
+}
 
-	_, err := pipe.Exec(redisCtx)
-	return err
+// searchVersion devolve a versão atual do namespace de busca do usuário
+// ("0" enquanto nada foi escrito).
+func searchVersion(userID uint) (string, error) {
+	version, err := redisClient.Client.Get(redisCtx, searchVersionKey(userID)).Result()
+	if err == redis.Nil {
+		return "0", nil
+	}
+	return version, err
 }
 
-func GetSearchHistory(userID uint) ([]string, error) {
+// InvalidateSearchCache muda a versão do namespace de busca do usuário e
+// atualiza o índice do autocomplete. Os resultados antigos não são
+// apagados: ficam órfãos e expiram pelo TTL. Deve ser chamada depois do
+// commit da escrita.
+func InvalidateSearchCache(userID uint) {
 	if redisClient == nil || redisClient.Client == nil {
-		return []string{}, nil
+		return
 	}
-
-	historyKey := "tmpro:search:history:" + strconv.Itoa(int(userID))
-
-	items, err := redisClient.Client.LRange(redisCtx, historyKey, 0, 9).Result()
-	if err != nil && err != redis.Nil {
-		return nil, err
+	if err := redisClient.Client.Incr(redisCtx, searchVersionKey(userID)).Err(); err != nil {
+		fmt.Println("Erro ao invalidar cache de busca:", err)
+	}
+	if err := syncSuggestIndex(userID); err != nil {
+		fmt.Println("Erro ao atualizar índice de sugestões:", err)
 	}
-
-	return items, nil
 }
@@ -0,0 +1,309 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Histórico de buscas: as últimas buscas ficam numa lista do Redis (sem
+// repetição, a mais recente no topo) e as fixadas numa lista separada, que
+// não passa pelo corte das últimas 10. Uma busca fica em uma das duas
+// listas, nunca nas duas; as escritas leem as listas sob WATCH.
+
+const (
+	maxSearchHistory  = 10
+	maxPinnedSearches = 20
+	maxHistoryQuery   = 200
+	maxHistoryRetries = 5 // tentativas quando outra escrita mexe nas listas
+)
+
+var (
+	ErrInvalidHistoryQuery = errors.New("invalid search history query")
+	ErrHistoryNotFound     = errors.New("query is not in the search history")
+	ErrTooManyPins         = fmt.Errorf("at most %d searches can be pinned", maxPinnedSearches)
+	ErrHistoryUnavailable  = errors.New("search history is unavailable")
+)
+
+func searchHistoryKey(userID uint) string {
+	return "tmpro:search:history:" + strconv.Itoa(int(userID))
+}
+
+func searchPinsKey(userID uint) string {
+	return "tmpro:search:pinned:" + strconv.Itoa(int(userID))
+}
+
+func historyAvailable() bool {
+	return redisClient != nil && redisClient.Client != nil
+}
+
+func normalizeHistoryQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("%w: query must not be empty", ErrInvalidHistoryQuery)
+	}
+	if len([]rune(query)) > maxHistoryQuery {
+		return "", fmt.Errorf("%w: query is longer than %d characters", ErrInvalidHistoryQuery, maxHistoryQuery)
+	}
+	return query, nil
+}
+
+// searchHistoryEnabled diz se o usuário grava histórico (padrão: sim).
+func searchHistoryEnabled(userID uint) (bool, error) {
+	var pref SearchPreference
+	err := database.DB.Where("user_id = ?", userID).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !pref.HistoryDisabled, nil
+}
+
+// sameQuery compara buscas sem diferenciar maiúsculas ("Foo" e "foo" são a
+// mesma entrada).
+func sameQuery(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// removeQuery agenda a remoção de todas as variações da busca na lista e
+// devolve quantas havia.
+func removeQuery(pipe redis.Pipeliner, key string, list []string, query string) int {
+	removed := 0
+	seen := map[string]bool{}
+	for _, q := range list {
+		if sameQuery(q, query) {
+			removed++
+			if !seen[q] {
+				seen[q] = true
+				pipe.LRem(redisCtx, key, 0, q)
+			}
+		}
+	}
+	return removed
+}
+
+// readHistoryLists lê as buscas fixadas e as recentes.
+func readHistoryLists(userID uint) (pins []string, recent []string, err error) {
+	pipe := redisClient.Client.Pipeline()
+	pinsCmd := pipe.LRange(redisCtx, searchPinsKey(userID), 0, -1)
+	recentCmd := pipe.LRange(redisCtx, searchHistoryKey(userID), 0, -1)
+	if _, err := pipe.Exec(redisCtx); err != nil && err != redis.Nil {
+		return nil, nil, err
+	}
+	return pinsCmd.Val(), recentCmd.Val(), nil
+}
+
+// updateHistoryLists lê as duas listas e aplica as escritas de fn numa
+// transação com WATCH nas duas chaves: se outra escrita mexer nelas entre a
+// leitura e o EXEC, lê de novo e tenta outra vez.
+func updateHistoryLists(userID uint, fn func(pipe redis.Pipeliner, pins, recent []string) error) error {
+	pinsKey, historyKey := searchPinsKey(userID), searchHistoryKey(userID)
+
+	var err error
+	for attempt := 0; attempt < maxHistoryRetries; attempt++ {
+		err = redisClient.Client.Watch(redisCtx, func(tx *redis.Tx) error {
+			pins, err := tx.LRange(redisCtx, pinsKey, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			recent, err := tx.LRange(redisCtx, historyKey, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(redisCtx, func(pipe redis.Pipeliner) error {
+				return fn(pipe, pins, recent)
+			})
+			return err
+		}, pinsKey, historyKey)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return err
+}
+
+// pushSearchHistory grava a busca no topo do histórico. Uma busca repetida
+// (mesmo com outra caixa) só sobe para o topo, sem duplicar; buscas fixadas
+// não entram nas recentes, para não ocupar as 10 posições.
+func pushSearchHistory(userID uint, query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" || len([]rune(query)) > maxHistoryQuery {
+		return nil
+	}
+
+	enabled, err := searchHistoryEnabled(userID)
+	if err != nil || !enabled {
+		return err
+	}
+
+	historyKey := searchHistoryKey(userID)
+	return updateHistoryLists(userID, func(pipe redis.Pipeliner, pins, recent []string) error {
+		for _, p := range pins {
+			if sameQuery(p, query) {
+				return nil
+			}
+		}
+
+		removeQuery(pipe, historyKey, recent, query)
+		pipe.LPush(redisCtx, historyKey, query)
+		pipe.LTrim(redisCtx, historyKey, 0, maxSearchHistory-1) // mantém apenas as últimas 10
+		return nil
+	})
+}
+
+// GetSearchHistory devolve as buscas fixadas e depois as recentes.
+func GetSearchHistory(userID uint) ([]SearchHistoryEntry, error) {
+	entries := []SearchHistoryEntry{}
+	if !historyAvailable() {
+		return entries, nil
+	}
+
+	pins, recent, err := readHistoryLists(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// históricos gravados antes das fixadas saírem das recentes podem ter a
+	// mesma busca nas duas listas
+	pinned := map[string]bool{}
+	for _, q := range pins {
+		pinned[strings.ToLower(q)] = true
+		entries = append(entries, SearchHistoryEntry{Query: q, Pinned: true})
+	}
+	for _, q := range recent {
+		if !pinned[strings.ToLower(q)] {
+			entries = append(entries, SearchHistoryEntry{Query: q})
+		}
+	}
+
+	return entries, nil
+}
+
+// DeleteSearchHistoryEntry remove a busca do histórico e das fixadas.
+func DeleteSearchHistoryEntry(userID uint, query string) error {
+	query, err := normalizeHistoryQuery(query)
+	if err != nil {
+		return err
+	}
+	if !historyAvailable() {
+		return ErrHistoryUnavailable
+	}
+
+	return updateHistoryLists(userID, func(pipe redis.Pipeliner, pins, recent []string) error {
+		removed := removeQuery(pipe, searchHistoryKey(userID), recent, query) +
+			removeQuery(pipe, searchPinsKey(userID), pins, query)
+		if removed == 0 {
+			return ErrHistoryNotFound
+		}
+		return nil
+	})
+}
+
+// ClearSearchHistory apaga todo o histórico, inclusive as buscas fixadas;
+// com keepPinned as fixadas continuam.
+func ClearSearchHistory(userID uint, keepPinned bool) error {
+	if !historyAvailable() {
+		return ErrHistoryUnavailable
+	}
+
+	keys := []string{searchHistoryKey(userID)}
+	if !keepPinned {
+		keys = append(keys, searchPinsKey(userID))
+	}
+	return redisClient.Client.Del(redisCtx, keys...).Err()
+}
+
+// PinSearch fixa a busca (a última fixada fica no topo) e a tira das
+// recentes. Não precisa estar no histórico.
+func PinSearch(userID uint, query string) ([]SearchHistoryEntry, error) {
+	query, err := normalizeHistoryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if !historyAvailable() {
+		return nil, ErrHistoryUnavailable
+	}
+
+	pinsKey := searchPinsKey(userID)
+	err = updateHistoryLists(userID, func(pipe redis.Pipeliner, pins, recent []string) error {
+		if removeQuery(pipe, pinsKey, pins, query) == 0 && len(pins) >= maxPinnedSearches {
+			return ErrTooManyPins
+		}
+		removeQuery(pipe, searchHistoryKey(userID), recent, query)
+		pipe.LPush(redisCtx, pinsKey, query)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSearchHistory(userID)
+}
+
+// UnpinSearch desafixa a busca, que volta para o topo das recentes.
+func UnpinSearch(userID uint, query string) error {
+	query, err := normalizeHistoryQuery(query)
+	if err != nil {
+		return err
+	}
+	if !historyAvailable() {
+		return ErrHistoryUnavailable
+	}
+
+	historyKey := searchHistoryKey(userID)
+	return updateHistoryLists(userID, func(pipe redis.Pipeliner, pins, recent []string) error {
+		var pinned string
+		for _, p := range pins {
+			if sameQuery(p, query) {
+				pinned = p
+				break
+			}
+		}
+		if pinned == "" {
+			return ErrHistoryNotFound
+		}
+
+		removeQuery(pipe, searchPinsKey(userID), pins, query)
+		removeQuery(pipe, historyKey, recent, query)
+		pipe.LPush(redisCtx, historyKey, pinned)
+		pipe.LTrim(redisCtx, historyKey, 0, maxSearchHistory-1)
+		return nil
+	})
+}
+
+func GetSearchHistorySettings(userID uint) (*SearchHistorySettings, error) {
+	enabled, err := searchHistoryEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchHistorySettings{Enabled: enabled}, nil
+}
+
+// UpdateSearchHistorySettings liga ou desliga a gravação do histórico.
+// Desligar também apaga as buscas recentes; as fixadas continuam.
+func UpdateSearchHistorySettings(userID uint, enabled bool) (*SearchHistorySettings, error) {
+	pref := SearchPreference{UserID: userID, HistoryDisabled: !enabled}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"history_disabled", "updated_at"}),
+	}).Create(&pref).Error; err != nil {
+		return nil, err
+	}
+
+	if !enabled && historyAvailable() {
+		if err := redisClient.Client.Del(redisCtx, searchHistoryKey(userID)).Err(); err != nil {
+			fmt.Println("Erro ao apagar histórico de busca:", err)
+		}
+	}
+
+	return &SearchHistorySettings{Enabled: enabled}, nil
+}
@@ -0,0 +1,151 @@
+package tasks
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/auth"
+)
+
+// writeSearchHistoryError mapeia os erros do histórico para o status HTTP.
+func writeSearchHistoryError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrInvalidHistoryQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrHistoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTooManyPins):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrHistoryUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func GetSearchHistoryHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	history, err := GetSearchHistory(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch search history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// ClearSearchHistoryHandler apaga todo o histórico; com keep_pinned=true as
+// buscas fixadas ficam.
+func ClearSearchHistoryHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := ClearSearchHistory(userID, c.Query("keep_pinned") == "true"); err != nil {
+		writeSearchHistoryError(c, err, "failed to clear search history")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteSearchHistoryEntryHandler remove uma busca: ?q=<query>.
+func DeleteSearchHistoryEntryHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := DeleteSearchHistoryEntry(userID, c.Query("q")); err != nil {
+		writeSearchHistoryError(c, err, "failed to delete search history entry")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func PinSearchHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input SearchHistoryQueryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := PinSearch(userID, input.Query)
+	if err != nil {
+		writeSearchHistoryError(c, err, "failed to pin search")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// UnpinSearchHandler desafixa uma busca: ?q=<query>.
+func UnpinSearchHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := UnpinSearch(userID, c.Query("q")); err != nil {
+		writeSearchHistoryError(c, err, "failed to unpin search")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func GetSearchHistorySettingsHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	settings, err := GetSearchHistorySettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch search history settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func UpdateSearchHistorySettingsHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input UpdateSearchHistorySettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := UpdateSearchHistorySettings(userID, *input.Enabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update search history settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
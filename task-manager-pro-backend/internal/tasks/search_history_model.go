@@ -0,0 +1,21 @@
+package tasks
+
+import "time"
+
+// SearchPreference guarda as preferências de busca do usuário. Fica no banco
+// (e não no Redis) para o opt-out do histórico sobreviver a um flush do cache.
+type SearchPreference struct {
+	UserID          uint      `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	HistoryDisabled bool      `json:"-" gorm:"not null;default:false"`
+	UpdatedAt       time.Time `json:"-"`
+}
+
+// SearchHistoryEntry é um item do histórico; as fixadas vêm primeiro.
+type SearchHistoryEntry struct {
+	Query  string `json:"query"`
+	Pinned bool   `json:"pinned"`
+}
+
+type SearchHistorySettings struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,287 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+// findUserTask garante que a task existe e pertence ao usuário.
+func findUserTask(db *gorm.DB, userID uint, id uint) (*Task, error) {
+	var task Task
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func CreateSubtask(userID uint, parentID uint, input CreateTaskInput) (*Task, error) {
+	parent, err := findUserTask(database.DB, userID, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// subtask herda o projeto do pai, a menos que outro seja informado
+	if input.ProjectID == nil && parent.ProjectID != nil {
+		var project Project
+		if err := database.DB.Where("id = ?", *parent.ProjectID).First(&project).Error; err != nil {
+			return nil, err
+		}
+		if project.Archived {
+			return nil, fmt.Errorf("%w: parent task is in archived project %q; unarchive the project, move the parent out of it or set project_id on the subtask",
+				ErrInvalidProject, project.Name)
+		}
+		input.ProjectID = parent.ProjectID
+	}
+
+	return createTask(userID, &parentID, input)
+}
+
+func ListSubtasks(userID uint, parentID uint) ([]Task, error) {
+	if _, err := findUserTask(database.DB, userID, parentID); err != nil {
+		return nil, err
+	}
+
+	var subtasks []Task
+	if err := database.DB.
+		Preload("Tags").
+		Where("user_id = ? AND parent_id = ?", userID, parentID).
+		Order("created_at ASC").
+		Find(&subtasks).Error; err != nil {
+		return nil, err
+	}
+
+	if err := enrichTasks(subtasks); err != nil {
+		return nil, err
+	}
+
+	return subtasks, nil
+}
+
+// descendantIDs retorna todos os IDs abaixo da task (filhos, netos, ...).
+func descendantIDs(db *gorm.DB, userID uint, rootID uint) ([]uint, error) {
+	var all []uint
+	frontier := []uint{rootID}
+
+	for len(frontier) > 0 {
+		var children []uint
+		if err := db.Model(&Task{}).
+			Where("user_id = ? AND parent_id IN ?", userID, frontier).
+			Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		all = append(all, children...)
+		frontier = children
+	}
+
+	return all, nil
+}
+
+// cascadeDone conclui as subtasks abertas e o checklist de uma task que
+// acabou de ser concluída. Cada subtask passa pelo updateTask, com as mesmas
+// regras de uma mudança de status normal (workflow, bloqueio, fim da coluna
+// DONE), e leva junto as próprias subtasks. As que não podem ser concluídas
+// (bloqueadas ou sem transição para DONE) ficam como estão.
+func cascadeDone(tx *gorm.DB, userID uint, taskID uint) error {
+	var pending []uint
+	if err := tx.Model(&Task{}).
+		Where("user_id = ? AND parent_id = ? AND status <> ?", userID, taskID, StatusDone).
+		Order("id ASC").
+		Pluck("id", &pending).Error; err != nil {
+		return err
+	}
+
+	// uma subtask bloqueada por uma irmã pode ser liberada na mesma passada,
+	// então repete enquanto alguma for concluída
+	done := StatusDone
+	for len(pending) > 0 {
+		var skipped []uint
+		for _, id := range pending {
+			_, err := updateTask(tx, userID, id, UpdateTaskInput{Status: &done})
+			switch {
+			case err == nil:
+			case errors.Is(err, ErrTaskBlocked), errors.Is(err, ErrIllegalTransition):
+				skipped = append(skipped, id)
+			default:
+				return err
+			}
+		}
+		if len(skipped) == len(pending) {
+			break
+		}
+		pending = skipped
+	}
+
+	return tx.Model(&ChecklistItem{}).
+		Where("task_id = ?", taskID).
+		Update("done", true).Error
+}
+
+// attachProgress calcula o progresso (subtasks + checklist) de cada task.
+func attachProgress(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+
+	type row struct {
+		ParentID uint
+		Total    int
+		Done     int
+	}
+
+	var subRows []row
+	if err := database.DB.Model(&Task{}).
+		Select("parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE status = 'DONE') AS done").
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&subRows).Error; err != nil {
+		return err
+	}
+
+	var itemRows []row
+	if err := database.DB.Model(&ChecklistItem{}).
+		Select("task_id AS parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE done) AS done").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&itemRows).Error; err != nil {
+		return err
+	}
+
+	progress := map[uint]*TaskProgress{}
+	for _, r := range append(subRows, itemRows...) {
+		p, ok := progress[r.ParentID]
+		if !ok {
+			p = &TaskProgress{}
+			progress[r.ParentID] = p
+		}
+		p.Total += r.Total
+		p.Done += r.Done
+	}
+
+	for i := range tasks {
+		if p, ok := progress[tasks[i].ID]; ok {
+			tasks[i].Progress = p
+		}
+	}
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Checklist
+// ---------------------------------------------------------------------------
+
+func ListChecklist(userID uint, taskID uint) ([]ChecklistItem, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	var items []ChecklistItem
+	if err := database.DB.
+		Where("task_id = ?", taskID).
+		Order("position ASC, id ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func AddChecklistItems(userID uint, taskID uint, titles []string) ([]ChecklistItem, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	items := []ChecklistItem{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&ChecklistItem{}).
+			Where("task_id = ?", taskID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+
+		for _, t := range titles {
+			title := strings.TrimSpace(t)
+			if title == "" {
+				continue
+			}
+			last++
+			items = append(items, ChecklistItem{
+				TaskID:   taskID,
+				Title:    title,
+				Position: last,
+			})
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ErrInvalidChecklistItem é o título vazio (só espaços) na edição; a criação
+// já ignora títulos vazios.
+var ErrInvalidChecklistItem = errors.New("checklist item title must not be empty")
+
+func UpdateChecklistItem(userID uint, taskID uint, itemID uint, input UpdateChecklistItemInput) (*ChecklistItem, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	var item ChecklistItem
+	if err := database.DB.Where("id = ? AND task_id = ?", itemID, taskID).First(&item).Error; err != nil {
+		return nil, err
+	}
+
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" {
+			return nil, ErrInvalidChecklistItem
+		}
+		item.Title = title
+	}
+	if input.Done != nil {
+		item.Done = *input.Done
+	}
+	if input.Position != nil {
+		item.Position = *input.Position
+	}
+
+	if err := database.DB.Save(&item).Error; err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func DeleteChecklistItem(userID uint, taskID uint, itemID uint) error {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return err
+	}
+
+	res := database.DB.Where("id = ? AND task_id = ?", itemID, taskID).Delete(&ChecklistItem{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
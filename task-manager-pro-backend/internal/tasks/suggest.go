@@ -0,0 +1,449 @@
+package tasks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// Autocomplete da busca. Tags e títulos do usuário ficam em dois sorted sets
+// do Redis (um por origem) com score 0, consultados por prefixo com
+// ZRANGEBYLEX. O índice é montado uma vez e depois mantido a cada escrita
+// (syncSuggestIndex, chamado pelo InvalidateSearchCache): só as tasks e tags
+// que mudaram desde a última sincronização são trocadas no índice.
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 20
+	maxSuggestPrefix    = 100
+
+	// quantos títulos recentes entram no índice montado do zero e por quanto
+	// tempo ele fica no Redis sem escrita
+	maxSuggestTitles = 2000
+	suggestIndexTTL  = 24 * time.Hour
+
+	// palavras do título indexadas (cada uma vira um ponto de entrada)
+	maxSuggestWords = 8
+
+	// entradas lidas de cada origem antes de ranquear
+	suggestScanCount = 100
+
+	// a sincronização relê as tasks alteradas um pouco antes da última,
+	// para pegar transações que terminaram depois dela (reprocessar é
+	// idempotente)
+	suggestSyncOverlap = time.Minute
+)
+
+// Origens das sugestões, na ordem em que aparecem.
+const (
+	SuggestHistory = "history"
+	SuggestTag     = "tag"
+	SuggestTitle   = "title"
+)
+
+type SearchSuggestion struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"` // history, tag, title
+}
+
+// suggestEntry é um membro do índice: key é o trecho (minúsculo) comparado
+// com o prefixo, text é o que vai para o usuário e source é o ID da task ou
+// tag de origem (duas tasks com o mesmo título têm membros diferentes).
+type suggestEntry struct {
+	key    string
+	kind   string
+	text   string
+	source string
+}
+
+func (e suggestEntry) member() string {
+	return e.key + "\x00" + e.text + "\x00" + e.source
+}
+
+func parseSuggestMember(kind, member string) (suggestEntry, bool) {
+	parts := strings.SplitN(member, "\x00", 3)
+	if len(parts) != 3 {
+		return suggestEntry{}, false
+	}
+	return suggestEntry{key: parts[0], kind: kind, text: parts[1], source: parts[2]}, true
+}
+
+// suggestEntries gera um ponto de entrada por palavra do texto ("fix login
+// bug" responde a "fix", "login" e "bug"); tags são quebradas por nível
+// ("client/acme" responde a "client" e "acme").
+func suggestEntries(kind, text, source string) []suggestEntry {
+	lower := strings.ToLower(text)
+	sep := " "
+	words := strings.Fields(lower)
+	if kind == SuggestTag {
+		sep = "/"
+		words = strings.Split(lower, "/")
+	}
+	if len(words) > maxSuggestWords {
+		words = words[:maxSuggestWords]
+	}
+
+	entries := make([]suggestEntry, 0, len(words))
+	for i := range words {
+		entries = append(entries, suggestEntry{key: strings.Join(words[i:], sep), kind: kind, text: text, source: source})
+	}
+	return entries
+}
+
+func suggestMembers(kind, text, source string) []any {
+	entries := suggestEntries(kind, text, source)
+	members := make([]any, len(entries))
+	for i, e := range entries {
+		members[i] = e.member()
+	}
+	return members
+}
+
+func suggestZ(kind, text, source string) []redis.Z {
+	entries := suggestEntries(kind, text, source)
+	members := make([]redis.Z, len(entries))
+	for i, e := range entries {
+		members[i] = redis.Z{Score: 0, Member: e.member()}
+	}
+	return members
+}
+
+// loadSuggestSources carrega do banco as tags e os títulos recentes do
+// usuário (ID -> texto).
+func loadSuggestSources(userID uint) (tags map[uint]string, titles map[uint]string, err error) {
+	var taskRows []struct {
+		ID    uint
+		Title string
+	}
+	if err := database.DB.Model(&Task{}).
+		Select("id, title").
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Limit(maxSuggestTitles).
+		Scan(&taskRows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var tagRows []struct {
+		ID   uint
+		Name string
+	}
+	if err := database.DB.Model(&Tag{}).
+		Select("id, name").
+		Where("user_id = ?", userID).
+		Scan(&tagRows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	tags = make(map[uint]string, len(tagRows))
+	for _, t := range tagRows {
+		tags[t.ID] = t.Name
+	}
+	titles = make(map[uint]string, len(taskRows))
+	for _, t := range taskRows {
+		titles[t.ID] = t.Title
+	}
+	return tags, titles, nil
+}
+
+// loadSuggestEntries monta as entradas direto do banco (sem Redis).
+func loadSuggestEntries(userID uint) ([]suggestEntry, error) {
+	tags, titles, err := loadSuggestSources(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []suggestEntry
+	for id, name := range tags {
+		entries = append(entries, suggestEntries(SuggestTag, name, strconv.Itoa(int(id)))...)
+	}
+	for id, title := range titles {
+		entries = append(entries, suggestEntries(SuggestTitle, title, strconv.Itoa(int(id)))...)
+	}
+	return entries, nil
+}
+
+// Chaves do índice: um sorted set e um hash (ID -> texto indexado) por
+// origem, e o instante da última sincronização.
+func suggestIndexKey(userID uint, kind string) string {
+	return "tmpro:suggest:index:" + strconv.Itoa(int(userID)) + ":" + kind
+}
+
+func suggestSourcesKey(userID uint, kind string) string {
+	return "tmpro:suggest:sources:" + strconv.Itoa(int(userID)) + ":" + kind
+}
+
+func suggestSyncedKey(userID uint) string {
+	return "tmpro:suggest:synced:" + strconv.Itoa(int(userID))
+}
+
+func suggestKeys(userID uint) []string {
+	return []string{
+		suggestIndexKey(userID, SuggestTag), suggestSourcesKey(userID, SuggestTag),
+		suggestIndexKey(userID, SuggestTitle), suggestSourcesKey(userID, SuggestTitle),
+		suggestSyncedKey(userID),
+	}
+}
+
+// ensureSuggestIndex monta o índice do zero se ele não existe (primeiro uso
+// ou expirou).
+func ensureSuggestIndex(userID uint) error {
+	exists, err := redisClient.Client.Exists(redisCtx, suggestSyncedKey(userID)).Result()
+	if err != nil || exists == 1 {
+		return err
+	}
+
+	started := time.Now()
+	tags, titles, err := loadSuggestSources(userID)
+	if err != nil {
+		return err
+	}
+
+	pipe := redisClient.Client.TxPipeline()
+	pipe.Del(redisCtx, suggestKeys(userID)...)
+	for kind, sources := range map[string]map[uint]string{SuggestTag: tags, SuggestTitle: titles} {
+		for id, text := range sources {
+			source := strconv.Itoa(int(id))
+			if members := suggestZ(kind, text, source); len(members) > 0 {
+				pipe.ZAdd(redisCtx, suggestIndexKey(userID, kind), members...)
+			}
+			pipe.HSet(redisCtx, suggestSourcesKey(userID, kind), source, text)
+		}
+	}
+	pipe.Set(redisCtx, suggestSyncedKey(userID), started.UnixNano(), 0)
+	for _, key := range suggestKeys(userID) {
+		pipe.Expire(redisCtx, key, suggestIndexTTL)
+	}
+	_, err = pipe.Exec(redisCtx)
+	return err
+}
+
+// syncSuggestIndex aplica no índice as escritas feitas desde a última
+// sincronização: tasks com updated_at mais novo (inclusive as que foram
+// para a lixeira ou voltaram dela) e as tags que mudaram, entraram ou
+// saíram. Sem índice montado não faz nada; o próximo suggest monta.
+func syncSuggestIndex(userID uint) error {
+	raw, err := redisClient.Client.Get(redisCtx, suggestSyncedKey(userID)).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	last, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return redisClient.Client.Del(redisCtx, suggestKeys(userID)...).Err()
+	}
+
+	started := time.Now()
+	since := time.Unix(0, last).Add(-suggestSyncOverlap)
+
+	var changed []struct {
+		ID        uint
+		Title     string
+		DeletedAt *time.Time
+	}
+	if err := database.DB.Unscoped().Model(&Task{}).
+		Select("id, title, deleted_at").
+		Where("user_id = ? AND updated_at >= ?", userID, since).
+		Scan(&changed).Error; err != nil {
+		return err
+	}
+
+	var tagRows []struct {
+		ID   uint
+		Name string
+	}
+	if err := database.DB.Model(&Tag{}).
+		Select("id, name").
+		Where("user_id = ?", userID).
+		Scan(&tagRows).Error; err != nil {
+		return err
+	}
+
+	// texto indexado hoje de cada origem
+	indexedTags, err := redisClient.Client.HGetAll(redisCtx, suggestSourcesKey(userID, SuggestTag)).Result()
+	if err != nil {
+		return err
+	}
+	indexedTitles := map[string]string{}
+	if len(changed) > 0 {
+		fields := make([]string, len(changed))
+		for i, t := range changed {
+			fields[i] = strconv.Itoa(int(t.ID))
+		}
+		values, err := redisClient.Client.HMGet(redisCtx, suggestSourcesKey(userID, SuggestTitle), fields...).Result()
+		if err != nil {
+			return err
+		}
+		for i, v := range values {
+			if text, ok := v.(string); ok {
+				indexedTitles[fields[i]] = text
+			}
+		}
+	}
+
+	pipe := redisClient.Client.TxPipeline()
+	replace := func(kind, source string, old string, hadOld bool, text string, keep bool) {
+		if hadOld && keep && old == text {
+			return
+		}
+		if hadOld {
+			if members := suggestMembers(kind, old, source); len(members) > 0 {
+				pipe.ZRem(redisCtx, suggestIndexKey(userID, kind), members...)
+			}
+			pipe.HDel(redisCtx, suggestSourcesKey(userID, kind), source)
+		}
+		if keep {
+			if members := suggestZ(kind, text, source); len(members) > 0 {
+				pipe.ZAdd(redisCtx, suggestIndexKey(userID, kind), members...)
+			}
+			pipe.HSet(redisCtx, suggestSourcesKey(userID, kind), source, text)
+		}
+	}
+
+	for _, t := range changed {
+		source := strconv.Itoa(int(t.ID))
+		old, hadOld := indexedTitles[source]
+		replace(SuggestTitle, source, old, hadOld, t.Title, t.DeletedAt == nil)
+	}
+
+	current := make(map[string]bool, len(tagRows))
+	for _, t := range tagRows {
+		source := strconv.Itoa(int(t.ID))
+		current[source] = true
+		old, hadOld := indexedTags[source]
+		replace(SuggestTag, source, old, hadOld, t.Name, true)
+	}
+	for source, old := range indexedTags {
+		if !current[source] {
+			replace(SuggestTag, source, old, true, "", false)
+		}
+	}
+
+	pipe.Set(redisCtx, suggestSyncedKey(userID), started.UnixNano(), 0)
+	for _, key := range suggestKeys(userID) {
+		pipe.Expire(redisCtx, key, suggestIndexTTL)
+	}
+	_, err = pipe.Exec(redisCtx)
+	return err
+}
+
+// lookupSuggestIndex busca no índice as entradas que começam com o prefixo.
+// Cada origem é lida separadamente, então as tags não ficam de fora quando
+// há muitos títulos com o mesmo prefixo.
+func lookupSuggestIndex(userID uint, prefix string) ([]suggestEntry, error) {
+	if err := ensureSuggestIndex(userID); err != nil {
+		return nil, err
+	}
+
+	entries := []suggestEntry{}
+	for _, kind := range []string{SuggestTag, SuggestTitle} {
+		members, err := redisClient.Client.ZRangeByLex(redisCtx, suggestIndexKey(userID, kind), &redis.ZRangeBy{
+			Min:   "[" + prefix,
+			Max:   "[" + prefix + "\xff",
+			Count: suggestScanCount,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+
+		for _, m := range members {
+			if e, ok := parseSuggestMember(kind, m); ok {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// SuggestSearch completa o prefixo com buscas recentes, tags e títulos, nessa
+// ordem. Sem Redis as entradas são lidas direto do banco.
+func SuggestSearch(userID uint, prefix string, limit int) ([]SearchSuggestion, error) {
+	prefix = strings.ToLower(strings.Join(strings.Fields(prefix), " "))
+	if prefix == "" || len([]rune(prefix)) > maxSuggestPrefix {
+		return []SearchSuggestion{}, nil
+	}
+	if limit <= 0 || limit > MaxSuggestLimit {
+		limit = DefaultSuggestLimit
+	}
+
+	suggestions := []SearchSuggestion{}
+	seen := map[string]bool{}
+	add := func(kind, text string) {
+		if len(suggestions) >= limit || seen[strings.ToLower(text)] {
+			return
+		}
+		seen[strings.ToLower(text)] = true
+		suggestions = append(suggestions, SearchSuggestion{Text: text, Kind: kind})
+	}
+
+	// 1. histórico (fixadas, depois as mais recentes)
+	history, err := GetSearchHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range history {
+		for _, e := range suggestEntries(SuggestHistory, h.Query, "") {
+			if strings.HasPrefix(e.key, prefix) {
+				add(SuggestHistory, h.Query)
+				break
+			}
+		}
+	}
+
+	// 2. tags e títulos
+	var entries []suggestEntry
+	if redisClient != nil && redisClient.Client != nil {
+		entries, err = lookupSuggestIndex(userID, prefix)
+		if err != nil {
+			fmt.Println("Erro no índice de sugestões:", err)
+			entries = nil
+		}
+	}
+	if entries == nil {
+		all, err := loadSuggestEntries(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range all {
+			if strings.HasPrefix(e.key, prefix) {
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	rankSuggestEntries(entries, prefix)
+	for _, e := range entries {
+		add(e.kind, e.text)
+	}
+
+	return suggestions, nil
+}
+
+// rankSuggestEntries ordena tags antes de títulos; dentro de cada origem,
+// quem começa com o prefixo vem antes de quem só tem uma palavra que começa,
+// depois os textos mais curtos.
+func rankSuggestEntries(entries []suggestEntry, prefix string) {
+	kindOrder := map[string]int{SuggestTag: 0, SuggestTitle: 1}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if kindOrder[a.kind] != kindOrder[b.kind] {
+			return kindOrder[a.kind] < kindOrder[b.kind]
+		}
+		aStart := strings.HasPrefix(strings.ToLower(a.text), prefix)
+		bStart := strings.HasPrefix(strings.ToLower(b.text), prefix)
+		if aStart != bStart {
+			return aStart
+		}
+		if len(a.text) != len(b.text) {
+			return len(a.text) < len(b.text)
+		}
+		return a.text < b.text
+	})
+}
@@ -0,0 +1,388 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTag = errors.New("invalid tag")
+	ErrTagExists  = errors.New("a tag with this name already exists; merge the tags instead")
+	ErrTagInUse   = errors.New("tag is still used by tasks; merge it or remove it from the tasks first")
+	ErrTagHasKids = errors.New("tag has child tags; move or remove them first")
+)
+
+// maxTagLength acompanha o tamanho da coluna tags.name.
+const maxTagLength = 100
+
+// normalizeTagPath limpa os segmentos do caminho: " client / acme " vira
+// "client/acme".
+func normalizeTagPath(raw string) string {
+	segments := []string{}
+	for _, s := range strings.Split(raw, "/") {
+		if s = strings.TrimSpace(s); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeLike escapa os curingas do LIKE.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ensureTagPath busca ou cria a tag e todos os níveis acima dela,
+// ligando cada uma ao pai.
+func ensureTagPath(db *gorm.DB, userID uint, path string) (*Tag, error) {
+	if len(path) > maxTagLength {
+		return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, path, maxTagLength)
+	}
+
+	var tag Tag
+	var parentID *uint
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		name := strings.Join(segments[:i+1], "/")
+
+		tag = Tag{}
+		err := db.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error
+		switch {
+		case err == nil:
+			// tags com "/" criadas antes da hierarquia ainda não têm pai
+			if tag.ParentID == nil && parentID != nil {
+				tag.ParentID = parentID
+				if err := db.Model(&tag).Update("parent_id", *parentID).Error; err != nil {
+					return nil, err
+				}
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			tag = Tag{UserID: userID, ParentID: parentID, Name: name}
+			if err := db.Create(&tag).Error; err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+
+		id := tag.ID
+		parentID = &id
+	}
+
+	return &tag, nil
+}
+
+// tagFilterCondition filtra por uma tag exata ou, com "client/*", pela tag
+// e todas as que estão abaixo dela.
+func tagFilterCondition(raw string) (string, []any) {
+	if prefix, ok := strings.CutSuffix(strings.TrimSpace(raw), "/*"); ok {
+		prefix = normalizeTagPath(prefix)
+		return "(t.name = ? OR t.name LIKE ?)", []any{prefix, escapeLike(prefix) + "/%"}
+	}
+	return "t.name = ?", []any{normalizeTagPath(raw)}
+}
+
+// linkTagParents liga ao pai as tags com "/" criadas antes da hierarquia.
+func linkTagParents(db *gorm.DB) error {
+	var orphans []Tag
+	if err := db.Where("name LIKE ? AND parent_id IS NULL", "%/%").Find(&orphans).Error; err != nil {
+		return err
+	}
+
+	for _, t := range orphans {
+		if normalizeTagPath(t.Name) != t.Name {
+			continue
+		}
+		if _, err := ensureTagPath(db, t.UserID, t.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildTagTree monta a árvore a partir da lista (ordenada por nome).
+func buildTagTree(tags []Tag) []Tag {
+	known := make(map[uint]bool, len(tags))
+	for _, t := range tags {
+		known[t.ID] = true
+	}
+
+	roots := []Tag{}
+	children := map[uint][]Tag{}
+	for _, t := range tags {
+		if t.ParentID != nil && known[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	var attach func(t Tag) Tag
+	attach = func(t Tag) Tag {
+		for _, c := range children[t.ID] {
+			t.Children = append(t.Children, attach(c))
+		}
+		return t
+	}
+	for i := range roots {
+		roots[i] = attach(roots[i])
+	}
+
+	return roots
+}
+
+func countChildTags(db *gorm.DB, tagID uint) (int64, error) {
+	var count int64
+	err := db.Model(&Tag{}).Where("parent_id = ?", tagID).Count(&count).Error
+	return count, err
+}
+
+func findUserTag(db *gorm.DB, userID uint, id uint) (*Tag, error) {
+	var tag Tag
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// ListTags lista as tags do usuário com a quantidade de tasks que as usam.
+// Com tree=true devolve só as raízes, com as filhas em children.
+func ListTags(userID uint, tree bool) ([]Tag, error) {
+	tags := []Tag{}
+	if err := database.DB.
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	if err := attachTagCounts(tags); err != nil {
+		return nil, err
+	}
+
+	if tree {
+		return buildTagTree(tags), nil
+	}
+	return tags, nil
+}
+
+// attachTagCounts conta as tasks fora da lixeira de cada tag.
+func attachTagCounts(tags []Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tags))
+	for i, t := range tags {
+		ids[i] = t.ID
+	}
+
+	var rows []struct {
+		TagID uint
+		Total int64
+	}
+	if err := database.DB.
+		Table("task_tags tt").
+		Select("tt.tag_id, COUNT(*) AS total").
+		Joins("JOIN tasks ON tasks.id = tt.task_id AND tasks.deleted_at IS NULL").
+		Where("tt.tag_id IN ?", ids).
+		Group("tt.tag_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, r := range rows {
+		counts[r.TagID] = r.Total
+	}
+	for i := range tags {
+		count := counts[tags[i].ID]
+		tags[i].TaskCount = &count
+	}
+
+	return nil
+}
+
+func getTag(userID uint, id uint) (*Tag, error) {
+	tag, err := findUserTag(database.DB, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []Tag{*tag}
+	if err := attachTagCounts(list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// UpdateTag renomeia e/ou muda a cor da tag. Renomear leva junto as tags
+// filhas (client -> customer move client/acme para customer/acme). Renomear
+// para o nome de outra tag do usuário é conflito (idx_user_tag): nesse caso
+// o caminho é o merge.
+func UpdateTag(userID uint, id uint, input UpdateTagInput) (*Tag, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tag, err := findUserTag(tx, userID, id)
+		if err != nil {
+			return err
+		}
+
+		if input.Name != nil {
+			if err := renameTag(tx, tag, normalizeTagPath(*input.Name)); err != nil {
+				return err
+			}
+		}
+		if input.Color != nil {
+			color, err := normalizeColor(*input.Color)
+			if err != nil {
+				return err
+			}
+			tag.Color = color
+		}
+
+		return tx.Save(tag).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return getTag(userID, id)
+}
+
+func renameTag(tx *gorm.DB, tag *Tag, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrInvalidTag)
+	}
+	if name == tag.Name {
+		return nil
+	}
+	if strings.HasPrefix(name, tag.Name+"/") {
+		return fmt.Errorf("%w: cannot move a tag under itself", ErrInvalidTag)
+	}
+
+	var descendants []Tag
+	if err := tx.Where("user_id = ? AND name LIKE ?", tag.UserID, escapeLike(tag.Name)+"/%").
+		Find(&descendants).Error; err != nil {
+		return err
+	}
+
+	moving := []uint{tag.ID}
+	newNames := []string{name}
+	for _, d := range descendants {
+		moving = append(moving, d.ID)
+		newNames = append(newNames, name+strings.TrimPrefix(d.Name, tag.Name))
+	}
+	for _, n := range newNames {
+		if len(n) > maxTagLength {
+			return fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, n, maxTagLength)
+		}
+	}
+
+	var count int64
+	if err := tx.Model(&Tag{}).
+		Where("user_id = ? AND name IN ? AND id NOT IN ?", tag.UserID, newNames, moving).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTagExists
+	}
+
+	// o novo caminho pode pedir um pai diferente (criado se não existir)
+	var parentID *uint
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		parent, err := ensureTagPath(tx, tag.UserID, name[:i])
+		if err != nil {
+			return err
+		}
+		parentID = &parent.ID
+	}
+
+	for i, d := range descendants {
+		if err := tx.Model(&Tag{}).Where("id = ?", d.ID).Update("name", newNames[i+1]).Error; err != nil {
+			return err
+		}
+	}
+
+	tag.Name = name
+	tag.ParentID = parentID
+	return nil
+}
+
+// MergeTag move todas as tasks da tag id para a tag intoID e apaga a tag de
+// origem. Tasks que já tinham as duas ficam só com a de destino.
+func MergeTag(userID uint, id uint, intoID uint) (*Tag, error) {
+	if id == intoID {
+		return nil, fmt.Errorf("%w: cannot merge a tag into itself", ErrInvalidTag)
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		source, err := findUserTag(tx, userID, id)
+		if err != nil {
+			return err
+		}
+		children, err := countChildTags(tx, source.ID)
+		if err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrTagHasKids
+		}
+		if _, err := findUserTag(tx, userID, intoID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: target tag %d not found", ErrInvalidTag, intoID)
+			}
+			return err
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO task_tags (task_id, tag_id)
+			SELECT task_id, ? FROM task_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, intoID, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(source).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return getTag(userID, intoID)
+}
+
+// DeleteTag apaga uma tag sem filhas que não está em uso (nem por tasks na
+// lixeira, para o restore continuar completo).
+func DeleteTag(userID uint, id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		tag, err := findUserTag(tx, userID, id)
+		if err != nil {
+			return err
+		}
+
+		children, err := countChildTags(tx, tag.ID)
+		if err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrTagHasKids
+		}
+
+		var count int64
+		if err := tx.Table("task_tags").Where("tag_id = ?", tag.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrTagInUse
+		}
+
+		return tx.Delete(tag).Error
+	})
+}
@@ -1,7 +1,16 @@
 package tasks
 
+// Tag aceita hierarquia por caminho: "client/acme" é filha de "client".
+// Name guarda o caminho completo (único por usuário) e ParentID aponta para
+// a tag do nível de cima.
 type Tag struct {
-	ID     uint   `json:"id" gorm:"primaryKey"`
-	UserID uint   `json:"user_id" gorm:"index"`
-	Name   string `json:"name" gorm:"size:50;index:idx_user_tag,unique"`
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	UserID   uint   `json:"user_id" gorm:"index:idx_user_tag,unique"`
+	ParentID *uint  `json:"parent_id" gorm:"index"`
+	Name     string `json:"name" gorm:"size:100;index:idx_user_tag,unique"`
+	Color    string `json:"color" gorm:"size:7"` // #RRGGBB
+
+	// Quantidade de tasks (fora da lixeira) com a tag; só na API de tags
+	TaskCount *int64 `json:"task_count,omitempty" gorm:"-"`
+	Children  []Tag  `json:"children,omitempty" gorm:"-"`
 }
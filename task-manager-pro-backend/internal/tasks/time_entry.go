@@ -0,0 +1,273 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTimerRunning     = errors.New("another timer is already running")
+	ErrNoRunningTimer   = errors.New("no timer is running")
+	ErrInvalidTimeEntry = errors.New("invalid time entry")
+	ErrInvalidTimeRange = errors.New("invalid time range")
+)
+
+// maxEntryDuration limita lançamentos manuais (evita erro de digitação virar 300h).
+const maxEntryDuration = 24 * time.Hour
+
+// entrySeconds é a duração de uma entrada; timers rodando contam até agora.
+const entrySeconds = `CASE WHEN time_entries.ended_at IS NULL
+	THEN EXTRACT(EPOCH FROM (NOW() - time_entries.started_at))::bigint
+	ELSE time_entries.duration_seconds END`
+
+// withElapsed preenche a duração dos timers que ainda estão rodando.
+func withElapsed(entries []TimeEntry) {
+	now := time.Now()
+	for i := range entries {
+		if entries[i].EndedAt == nil {
+			entries[i].DurationSeconds = int64(now.Sub(entries[i].StartedAt).Seconds())
+		}
+	}
+}
+
+func findRunningTimer(db *gorm.DB, userID uint) (*TimeEntry, error) {
+	var entry TimeEntry
+	if err := db.Where("user_id = ? AND ended_at IS NULL", userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RunningTimer devolve o timer que está rodando para o usuário.
+func RunningTimer(userID uint) (*TimeEntry, error) {
+	entry, err := findRunningTimer(database.DB, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoRunningTimer
+		}
+		return nil, err
+	}
+
+	list := []TimeEntry{*entry}
+	withElapsed(list)
+	return &list[0], nil
+}
+
+// StartTimer inicia um timer na task. Só pode existir um rodando por usuário.
+func StartTimer(userID uint, taskID uint) (*TimeEntry, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	_, err := findRunningTimer(database.DB, userID)
+	if err == nil {
+		return nil, ErrTimerRunning
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	entry := &TimeEntry{UserID: userID, TaskID: taskID, StartedAt: time.Now()}
+	if err := database.DB.Create(entry).Error; err != nil {
+		// outro start concorrente ganhou a corrida no índice único
+		if _, findErr := findRunningTimer(database.DB, userID); findErr == nil {
+			return nil, ErrTimerRunning
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// StopTimer para o timer que está rodando e grava a duração.
+func StopTimer(userID uint, note *string) (*TimeEntry, error) {
+	entry, err := findRunningTimer(database.DB, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoRunningTimer
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	entry.EndedAt = &now
+	entry.DurationSeconds = int64(now.Sub(entry.StartedAt).Seconds())
+	if note != nil {
+		entry.Note = *note
+	}
+
+	if err := database.DB.Save(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func ListTimeEntries(userID uint, taskID uint) (*TaskTimeLog, error) {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	timeLog := &TaskTimeLog{Items: []TimeEntry{}}
+	if err := database.DB.
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("started_at DESC, id DESC").
+		Find(&timeLog.Items).Error; err != nil {
+		return nil, err
+	}
+
+	withElapsed(timeLog.Items)
+	for _, e := range timeLog.Items {
+		timeLog.TotalSeconds += e.DurationSeconds
+	}
+
+	return timeLog, nil
+}
+
+// AddTimeEntry registra tempo trabalhado sem timer (lançamento manual).
+func AddTimeEntry(userID uint, taskID uint, input TimeEntryInput) (*TimeEntry, error) {
+	var duration time.Duration
+	switch {
+	case input.EndedAt != nil && input.DurationMinutes != 0:
+		return nil, fmt.Errorf("%w: set either ended_at or duration_minutes", ErrInvalidTimeEntry)
+	case input.EndedAt != nil:
+		duration = input.EndedAt.Sub(input.StartedAt)
+	default:
+		duration = time.Duration(input.DurationMinutes) * time.Minute
+	}
+	if duration < time.Minute || duration > maxEntryDuration {
+		return nil, fmt.Errorf("%w: duration must be between 1 minute and %s", ErrInvalidTimeEntry, maxEntryDuration)
+	}
+
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	ended := input.StartedAt.Add(duration)
+	entry := &TimeEntry{
+		UserID:          userID,
+		TaskID:          taskID,
+		StartedAt:       input.StartedAt,
+		EndedAt:         &ended,
+		DurationSeconds: int64(duration.Seconds()),
+		Note:            input.Note,
+		Manual:          true,
+	}
+	if err := database.DB.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func DeleteTimeEntry(userID uint, taskID uint, entryID uint) error {
+	if _, err := findUserTask(database.DB, userID, taskID); err != nil {
+		return err
+	}
+
+	res := database.DB.
+		Where("id = ? AND task_id = ? AND user_id = ?", entryID, taskID, userID).
+		Delete(&TimeEntry{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// timeEntriesQuery filtra as entradas do usuário no período [from, to),
+// ignorando tasks que estão na lixeira.
+func timeEntriesQuery(userID uint, from, to *time.Time) *gorm.DB {
+	db := database.DB.
+		Table("time_entries").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id AND tasks.deleted_at IS NULL").
+		Where("time_entries.user_id = ?", userID)
+	if from != nil {
+		db = db.Where("time_entries.started_at >= ?", *from)
+	}
+	if to != nil {
+		db = db.Where("time_entries.started_at < ?", *to)
+	}
+	return db
+}
+
+// TimeTotals soma o tempo por task ou por tag. Uma task com várias tags
+// conta o tempo em cada uma delas.
+func TimeTotals(userID uint, groupBy string, from, to *time.Time) ([]TimeTotal, error) {
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidTimeRange)
+	}
+
+	db := timeEntriesQuery(userID, from, to)
+	switch groupBy {
+	case "", "task":
+		db = db.Select("tasks.id AS id, tasks.title AS name, SUM(" + entrySeconds + ")::bigint AS seconds").
+			Group("tasks.id, tasks.title")
+	case "tag":
+		db = db.Joins("JOIN task_tags tt ON tt.task_id = tasks.id").
+			Joins("JOIN tags t ON t.id = tt.tag_id").
+			Select("t.id AS id, t.name AS name, SUM(" + entrySeconds + ")::bigint AS seconds").
+			Group("t.id, t.name")
+	default:
+		return nil, fmt.Errorf("%w: group_by must be 'task' or 'tag'", ErrInvalidTimeRange)
+	}
+
+	totals := []TimeTotal{}
+	if err := db.Order("seconds DESC, id ASC").Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// TimeReport agrupa o tempo registrado em [from, to) por dia ou semana (UTC,
+// semanas começando na segunda). A entrada conta no período em que começou.
+func TimeReport(userID uint, interval string, from, to time.Time) ([]TimeReportBucket, error) {
+	if interval == "" {
+		interval = "day"
+	}
+	if interval != "day" && interval != "week" {
+		return nil, fmt.Errorf("%w: interval must be 'day' or 'week'", ErrInvalidTimeRange)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidTimeRange)
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return nil, fmt.Errorf("%w: range must be at most one year", ErrInvalidTimeRange)
+	}
+
+	var rows []struct {
+		Period  time.Time
+		TaskID  uint
+		Title   string
+		Seconds int64
+	}
+	period := "date_trunc('" + interval + "', time_entries.started_at AT TIME ZONE 'UTC')"
+	if err := timeEntriesQuery(userID, &from, &to).
+		Select(period + " AS period, tasks.id AS task_id, tasks.title AS title, SUM(" + entrySeconds + ")::bigint AS seconds").
+		Group("period, tasks.id, tasks.title").
+		Order("period ASC, seconds DESC, tasks.id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	buckets := []TimeReportBucket{}
+	for _, r := range rows {
+		p := time.Date(r.Period.Year(), r.Period.Month(), r.Period.Day(), 0, 0, 0, 0, time.UTC)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Period.Equal(p) {
+			buckets = append(buckets, TimeReportBucket{Period: p, Tasks: []TimeTotal{}})
+		}
+		b := &buckets[len(buckets)-1]
+		b.Seconds += r.Seconds
+		b.Tasks = append(b.Tasks, TimeTotal{ID: r.TaskID, Name: r.Title, Seconds: r.Seconds})
+	}
+
+	return buckets, nil
+}
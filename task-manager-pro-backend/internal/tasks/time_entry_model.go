@@ -0,0 +1,38 @@
+package tasks
+
+import "time"
+
+// TimeEntry é um registro de tempo trabalhado numa task: um timer (ended_at
+// nil enquanto roda) ou um lançamento manual. O índice parcial garante um
+// único timer rodando por usuário.
+type TimeEntry struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"index;uniqueIndex:idx_one_running_timer,where:ended_at IS NULL"`
+	TaskID          uint       `json:"task_id" gorm:"index"`
+	StartedAt       time.Time  `json:"started_at" gorm:"index"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	Note            string     `json:"note" gorm:"type:text"`
+	Manual          bool       `json:"manual"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type TaskTimeLog struct {
+	Items        []TimeEntry `json:"items"`
+	TotalSeconds int64       `json:"total_seconds"`
+}
+
+// TimeTotal é o tempo somado de uma task ou de uma tag.
+type TimeTotal struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Seconds int64  `json:"seconds"`
+}
+
+// TimeReportBucket agrupa o tempo de um dia/semana, com o detalhe por task.
+type TimeReportBucket struct {
+	Period  time.Time   `json:"period"`
+	Seconds int64       `json:"seconds"`
+	Tasks   []TimeTotal `json:"tasks"`
+}
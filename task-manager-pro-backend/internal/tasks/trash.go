@@ -0,0 +1,348 @@
+package tasks
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var ErrParentInTrash = errors.New("parent task is in the trash; restore it first")
+
+// softDeleteTree manda a task e todas as subtasks para a lixeira com o mesmo
+// deleted_at, que é o que o restore usa para trazer o grupo de volta junto.
+func softDeleteTree(tx *gorm.DB, userID uint, task *Task) error {
+	ids, err := descendantIDs(tx, userID, task.ID)
+	if err != nil {
+		return err
+	}
+	all := append(ids, task.ID)
+
+	var deleted []Task
+	if err := tx.Where("id IN ?", all).Find(&deleted).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&Task{}).
+		Where("id IN ?", all).
+		Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+
+	for i := range deleted {
+		if err := recordAction(tx, userID, &deleted[i], ActivityDeleted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unscoped devolve uma sessão reutilizável que enxerga tasks na lixeira
+// (o Unscoped() puro não pode ser reaproveitado em várias queries).
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Session(&gorm.Session{})
+}
+
+func ListTrash(userID uint) ([]Task, error) {
+	var trashed []Task
+	if err := database.DB.
+		Unscoped().
+		Preload("Tags").
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&trashed).Error; err != nil {
+		return nil, err
+	}
+
+	return trashed, nil
+}
+
+func findTrashedTask(db *gorm.DB, userID uint, id uint) (*Task, error) {
+	var task Task
+	if err := db.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// RestoreTask tira a task da lixeira junto com as subtasks que foram
+// deletadas na mesma operação. Tags, checklist e dependências voltam junto
+// porque nunca foram desligadas.
+func RestoreTask(userID uint, id uint) (*Task, error) {
+	task, err := findTrashedTask(database.DB, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.ParentID != nil {
+		if _, err := findUserTask(database.DB, userID, *task.ParentID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrParentInTrash
+			}
+			return nil, err
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		ids, err := descendantIDs(unscoped(tx), userID, task.ID)
+		if err != nil {
+			return err
+		}
+
+		var restored []Task
+		if err := tx.Unscoped().
+			Where("id IN ? AND deleted_at = ?", append(ids, task.ID), task.DeletedAt.Time).
+			Find(&restored).Error; err != nil {
+			return err
+		}
+
+		restoredIDs := make([]uint, len(restored))
+		for i, t := range restored {
+			restoredIDs[i] = t.ID
+		}
+
+		if err := tx.Unscoped().Model(&Task{}).
+			Where("id IN ?", restoredIDs).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		for i := range restored {
+			if err := recordAction(tx, userID, &restored[i], ActivityRestored); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetTaskByID(userID, id)
+}
+
+// PurgeTask apaga definitivamente uma task que está na lixeira.
+func PurgeTask(userID uint, id uint) error {
+	task, err := findTrashedTask(database.DB, userID, id)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		keys, err = purgeTree(tx, userID, task.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	removeAttachmentFiles(keys)
+	return nil
+}
+
+// EmptyTrash apaga definitivamente tudo que está na lixeira do usuário.
+func EmptyTrash(userID uint) (int, error) {
+	var ids []uint
+	if err := database.DB.Unscoped().Model(&Task{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var keys []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		keys, err = purgeTasks(tx, userID, ids)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	removeAttachmentFiles(keys)
+	return len(ids), nil
+}
+
+func purgeTree(tx *gorm.DB, userID uint, taskID uint) ([]string, error) {
+	ids, err := descendantIDs(unscoped(tx), userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return purgeTasks(tx, userID, append(ids, taskID))
+}
+
+// purgeTasks remove as tasks e tudo que depende delas (tags, checklist,
+// dependências, histórico de status, comentários, lembretes, tempo registrado,
+// campos customizados, anexos). Retorna as chaves dos arquivos anexados, que só devem ser apagados
+// depois do commit.
+func purgeTasks(tx *gorm.DB, userID uint, ids []uint) ([]string, error) {
+	var purged []Task
+	if err := tx.Unscoped().Where("id IN ? AND user_id = ?", ids, userID).Find(&purged).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("task_id IN ?", ids).Delete(&ChecklistItem{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id IN ?", ids).Error; err != nil {
+		return nil, err
+	}
+	if err := deleteDependencies(tx, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&TaskStatusTransition{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&TaskComment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&TaskReminder{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&TimeEntry{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&TaskFieldValue{}).Error; err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	if err := tx.Model(&TaskAttachment{}).Where("task_id IN ?", ids).Pluck("storage_key", &keys).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&TaskAttachment{}).Error; err != nil {
+		return nil, err
+	}
+
+	// subtasks que não estão sendo apagadas perdem o pai
+	if err := unscoped(tx).Model(&Task{}).
+		Where("parent_id IN ? AND id NOT IN ?", ids, ids).
+		Update("parent_id", nil).Error; err != nil {
+		return nil, err
+	}
+
+	if err := unlinkPurgedOccurrences(tx, purged, ids); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Unscoped().Where("id IN ? AND user_id = ?", ids, userID).Delete(&Task{}).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range purged {
+		if err := recordAction(tx, userID, &purged[i], ActivityPurged); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// unlinkPurgedOccurrences solta as séries recorrentes das tasks apagadas: a
+// ocorrência anterior volta a poder gerar a próxima e, se a raiz sai, a
+// ocorrência mais antiga que sobrou vira a nova raiz e herda o contador.
+func unlinkPurgedOccurrences(tx *gorm.DB, purged []Task, ids []uint) error {
+	if err := unscoped(tx).Model(&Task{}).
+		Where("next_occurrence_id IN ? AND id NOT IN ?", ids, ids).
+		UpdateColumn("next_occurrence_id", nil).Error; err != nil {
+		return err
+	}
+
+	for _, root := range purged {
+		if root.SeriesID != nil {
+			continue
+		}
+
+		var members []uint
+		if err := unscoped(tx).Model(&Task{}).
+			Where("series_id = ? AND id NOT IN ?", root.ID, ids).
+			Order("id ASC").
+			Pluck("id", &members).Error; err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		newRoot := members[0]
+		if err := unscoped(tx).Model(&Task{}).
+			Where("id = ?", newRoot).
+			UpdateColumns(map[string]any{"series_id": nil, "recurrence_count": root.RecurrenceCount}).Error; err != nil {
+			return err
+		}
+		if err := unscoped(tx).Model(&Task{}).
+			Where("series_id = ? AND id NOT IN ?", root.ID, ids).
+			UpdateColumn("series_id", newRoot).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeExpiredTrash apaga definitivamente as tasks que estão na lixeira há
+// mais tempo que o período de retenção.
+func PurgeExpiredTrash(retention time.Duration) (int, error) {
+	var expired []Task
+	if err := database.DB.Unscoped().
+		Select("id", "user_id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-retention)).
+		Limit(500).
+		Find(&expired).Error; err != nil {
+		return 0, err
+	}
+
+	byUser := map[uint][]uint{}
+	for _, t := range expired {
+		byUser[t.UserID] = append(byUser[t.UserID], t.ID)
+	}
+
+	purged := 0
+	for userID, ids := range byUser {
+		var keys []string
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			keys, err = purgeTasks(tx, userID, ids)
+			return err
+		})
+		if err != nil {
+			log.Printf("[trash] user=%d purge failed: %v", userID, err)
+			continue
+		}
+		removeAttachmentFiles(keys)
+		InvalidateSearchCache(userID)
+		purged += len(ids)
+	}
+
+	return purged, nil
+}
+
+func StartTrashPurger(retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for ; ; <-ticker.C {
+			n, err := PurgeExpiredTrash(retention)
+			if err != nil {
+				log.Println("[trash] purge failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("[trash] purged %d task(s)", n)
+			}
+		}
+	}()
+}
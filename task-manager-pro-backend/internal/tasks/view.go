@@ -0,0 +1,200 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidView = errors.New("invalid view")
+	ErrViewExists  = errors.New("a view with this name already exists")
+)
+
+// taskFilter converte os filtros salvos no TaskFilter usado pelo ListTasks.
+func (f ViewFilter) taskFilter() TaskFilter {
+	filter := TaskFilter{
+		Status:       f.Status,
+		Priority:     f.Priority,
+		Tags:         f.Tags,
+		Query:        f.Query,
+		ProjectID:    f.ProjectID,
+		DueBefore:    f.DueBefore,
+		DueAfter:     f.DueAfter,
+		Overdue:      f.Overdue,
+		CreatedSince: f.CreatedSince,
+		Expr:         f.Expr,
+		Sort:         f.Sort,
+		Order:        f.Order,
+	}
+
+	for param, value := range f.CustomFields {
+		key, op, _ := strings.Cut(param, ".")
+		filter.CustomFields = append(filter.CustomFields, CustomFieldFilter{Key: key, Op: op, Value: value})
+	}
+	sortCustomFieldFilters(filter.CustomFields)
+
+	return filter
+}
+
+// validateViewFilter roda a mesma normalização do ListTasks para recusar
+// ordenação ou campos customizados inválidos já ao salvar.
+func validateViewFilter(userID uint, f ViewFilter) error {
+	filter := f.taskFilter()
+	if err := prepareFilter(userID, &filter); err != nil {
+		return err
+	}
+	if f.ProjectID != nil {
+		var count int64
+		if err := database.DB.Model(&Project{}).
+			Where("id = ? AND user_id = ?", *f.ProjectID, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: project %d not found", ErrInvalidView, *f.ProjectID)
+		}
+	}
+	return nil
+}
+
+func viewNameTaken(db *gorm.DB, userID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Model(&SavedView{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func ListViews(userID uint) ([]SavedView, error) {
+	views := []SavedView{}
+	if err := database.DB.
+		Where("user_id = ?", userID).
+		Order("pinned DESC, position ASC, id ASC").
+		Find(&views).Error; err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+func GetView(userID uint, id uint) (*SavedView, error) {
+	var view SavedView
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&view).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func CreateView(userID uint, input CreateViewInput) (*SavedView, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name must not be empty", ErrInvalidView)
+	}
+	if err := validateViewFilter(userID, input.Filter); err != nil {
+		return nil, err
+	}
+
+	taken, err := viewNameTaken(database.DB, userID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrViewExists
+	}
+
+	view := &SavedView{
+		UserID: userID,
+		Name:   name,
+		Filter: input.Filter,
+		Pinned: input.Pinned,
+	}
+	if input.Position != nil {
+		view.Position = *input.Position
+	} else {
+		// sem posição: vai para o fim da lista
+		var last int
+		if err := database.DB.Model(&SavedView{}).
+			Where("user_id = ?", userID).
+			Select("COALESCE(MAX(position), -1)").
+			Scan(&last).Error; err != nil {
+			return nil, err
+		}
+		view.Position = last + 1
+	}
+
+	if err := database.DB.Create(view).Error; err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func UpdateView(userID uint, id uint, input UpdateViewInput) (*SavedView, error) {
+	view, err := GetView(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name must not be empty", ErrInvalidView)
+		}
+		taken, err := viewNameTaken(database.DB, userID, name, view.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrViewExists
+		}
+		view.Name = name
+	}
+	if input.Filter != nil {
+		if err := validateViewFilter(userID, *input.Filter); err != nil {
+			return nil, err
+		}
+		view.Filter = *input.Filter
+	}
+	if input.Pinned != nil {
+		view.Pinned = *input.Pinned
+	}
+	if input.Position != nil {
+		view.Position = *input.Position
+	}
+
+	if err := database.DB.Save(view).Error; err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func DeleteView(userID uint, id uint) error {
+	res := database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&SavedView{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListViewTasks executa a view pelo ListTasks; só a paginação vem da request.
+func ListViewTasks(userID uint, id uint, page TaskFilter) (*TaskPage, error) {
+	view, err := GetView(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := view.Filter.taskFilter()
+	filter.Cursor = page.Cursor
+	filter.Limit = page.Limit
+	filter.WithTotal = page.WithTotal
+
+	return ListTasks(userID, filter)
+}
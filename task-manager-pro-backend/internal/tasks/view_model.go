@@ -0,0 +1,37 @@
+package tasks
+
+import "time"
+
+// SavedView é um conjunto de filtros + ordenação salvo pelo usuário.
+// Views fixadas (pinned) vêm primeiro; dentro de cada grupo vale Position.
+type SavedView struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index:idx_user_view,unique"`
+	Name      string     `json:"name" gorm:"size:100;index:idx_user_view,unique"`
+	Filter    ViewFilter `json:"filter" gorm:"serializer:json"`
+	Pinned    bool       `json:"pinned" gorm:"not null;default:false"`
+	Position  int        `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ViewFilter é o TaskFilter salvo na view, com os mesmos nomes da query
+// string de GET /api/tasks.
+type ViewFilter struct {
+	Status       string     `json:"status,omitempty"`
+	Priority     string     `json:"priority,omitempty"`
+	Tags         string     `json:"tags,omitempty"`
+	Query        string     `json:"q,omitempty"`
+	ProjectID    *uint      `json:"project_id,omitempty"`
+	DueBefore    *time.Time `json:"due_before,omitempty"`
+	DueAfter     *time.Time `json:"due_after,omitempty"`
+	Overdue      bool       `json:"overdue,omitempty"`
+	CreatedSince *time.Time `json:"created_since,omitempty"`
+	Expr         string     `json:"query,omitempty"` // linguagem de busca, ver query.go
+
+	// "<key>" ou "<key>.<op>" -> valor, como nos parâmetros cf.<key>[.<op>]
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+
+	Sort  string `json:"sort,omitempty"`
+	Order string `json:"order,omitempty"`
+}
@@ -0,0 +1,391 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidStatus      = errors.New("invalid status")
+	ErrInvalidPriority    = errors.New("invalid priority")
+	ErrIllegalTransition  = errors.New("illegal status transition")
+	ErrStateExists        = errors.New("workflow state already exists")
+	ErrStateInUse         = errors.New("workflow state is used by tasks (including tasks in the trash)")
+	ErrDefaultWorkflow    = errors.New("default workflow states and transitions cannot be changed")
+	ErrTransitionNotFound = errors.New("workflow transition not found")
+)
+
+const StatusDone = "DONE"
+
+var (
+	defaultStates = []string{"TODO", "IN_PROGRESS", StatusDone}
+
+	defaultTransitions = [][2]string{
+		{"TODO", "IN_PROGRESS"},
+		{"IN_PROGRESS", "TODO"},
+		{"IN_PROGRESS", StatusDone},
+		{StatusDone, "IN_PROGRESS"},
+	}
+
+	priorities = []string{"LOW", "MEDIUM", "HIGH"}
+
+	// Variações que já existem no banco / no front antigo
+	statusAliases = map[string]string{
+		"DOING":       "IN_PROGRESS",
+		"IN PROGRESS": "IN_PROGRESS",
+		"INPROGRESS":  "IN_PROGRESS",
+		"TO DO":       "TODO",
+	}
+)
+
+// normalizeStatus deixa o status em maiúsculas com "_" no lugar de espaços
+// e hífens, resolvendo os aliases ("to-do" e "To Do" viram TODO).
+func normalizeStatus(s string) string {
+	up := strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToUpper(s))
+	key := strings.Join(strings.Fields(up), " ")
+	if alias, ok := statusAliases[key]; ok {
+		return alias
+	}
+	return strings.ReplaceAll(key, " ", "_")
+}
+
+func normalizePriority(p string) (string, error) {
+	up := strings.ToUpper(strings.TrimSpace(p))
+	for _, valid := range priorities {
+		if up == valid {
+			return up, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q (allowed: %s)", ErrInvalidPriority, p, strings.Join(priorities, ", "))
+}
+
+type Workflow struct {
+	States      []string        `json:"states"`
+	Transitions []WorkflowEdge  `json:"transitions"`
+	Custom      []WorkflowState `json:"custom_states"`
+}
+
+type WorkflowEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Custom bool   `json:"custom"`
+}
+
+func LoadWorkflow(db *gorm.DB, userID uint) (*Workflow, error) {
+	wf := &Workflow{
+		States: append([]string{}, defaultStates...),
+		Custom: []WorkflowState{},
+	}
+	for _, t := range defaultTransitions {
+		wf.Transitions = append(wf.Transitions, WorkflowEdge{From: t[0], To: t[1]})
+	}
+
+	if err := db.Where("user_id = ?", userID).
+		Order("position ASC, id ASC").
+		Find(&wf.Custom).Error; err != nil {
+		return nil, err
+	}
+	for _, st := range wf.Custom {
+		wf.States = append(wf.States, st.Name)
+	}
+
+	var custom []WorkflowTransition
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range custom {
+		wf.Transitions = append(wf.Transitions, WorkflowEdge{From: t.FromStatus, To: t.ToStatus, Custom: true})
+	}
+
+	return wf, nil
+}
+
+// InitialState é o estado em que tasks entram quando nenhum é informado
+// (ocorrências de recorrência, status legados desconhecidos).
+func (wf *Workflow) InitialState() string {
+	return wf.States[0]
+}
+
+func (wf *Workflow) HasState(status string) bool {
+	for _, s := range wf.States {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (wf *Workflow) CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, t := range wf.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateStatus normaliza o status e garante que ele existe no workflow.
+func (wf *Workflow) ValidateStatus(status string) (string, error) {
+	s := normalizeStatus(status)
+	if !wf.HasState(s) {
+		return "", fmt.Errorf("%w %q (allowed: %s)", ErrInvalidStatus, status, strings.Join(wf.States, ", "))
+	}
+	return s, nil
+}
+
+// ValidateTransition checa se from -> to é permitido.
+func (wf *Workflow) ValidateTransition(from, to string) error {
+	if !wf.CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+	return nil
+}
+
+// normalizeTaskStatuses traz para o workflow os status gravados antes dele:
+// aliases e variações de escrita são normalizados e o que continuar fora
+// do workflow do usuário vai para o estado inicial (senão a task nunca mais
+// teria transição válida). Inclui as tasks na lixeira, para o restore.
+func normalizeTaskStatuses(db *gorm.DB) error {
+	var rows []struct {
+		UserID uint
+		Status string
+	}
+	if err := db.Unscoped().Model(&Task{}).Distinct("user_id", "status").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	workflows := map[uint]*Workflow{}
+	for _, r := range rows {
+		wf, ok := workflows[r.UserID]
+		if !ok {
+			var err error
+			if wf, err = LoadWorkflow(db, r.UserID); err != nil {
+				return err
+			}
+			workflows[r.UserID] = wf
+		}
+
+		status := normalizeStatus(r.Status)
+		if !wf.HasState(status) {
+			log.Printf("[workflow] user=%d unknown status %q moved to %s", r.UserID, r.Status, wf.InitialState())
+			status = wf.InitialState()
+		}
+		if status == r.Status {
+			continue
+		}
+
+		if err := db.Unscoped().Model(&Task{}).
+			Where("user_id = ? AND status = ?", r.UserID, r.Status).
+			UpdateColumn("status", status).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recordTransition(tx *gorm.DB, task *Task, from, to string) error {
+	if from == to {
+		return nil
+	}
+	return tx.Create(&TaskStatusTransition{
+		TaskID:     task.ID,
+		UserID:     task.UserID,
+		FromStatus: from,
+		ToStatus:   to,
+	}).Error
+}
+
+// ---------------------------------------------------------------------------
+// Histórico de transições
+// ---------------------------------------------------------------------------
+
+type TaskStatusHistory struct {
+	Transitions []TaskStatusTransition `json:"transitions"`
+	// segundos que a task passou em cada status
+	TimeInStatus map[string]int64 `json:"time_in_status"`
+}
+
+func GetStatusHistory(userID uint, taskID uint) (*TaskStatusHistory, error) {
+	task, err := findUserTask(database.DB, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transitions []TaskStatusTransition
+	if err := database.DB.
+		Where("task_id = ?", taskID).
+		Order("created_at ASC, id ASC").
+		Find(&transitions).Error; err != nil {
+		return nil, err
+	}
+
+	return &TaskStatusHistory{
+		Transitions:  transitions,
+		TimeInStatus: timeInStatus(task, transitions, time.Now()),
+	}, nil
+}
+
+func timeInStatus(task *Task, transitions []TaskStatusTransition, now time.Time) map[string]int64 {
+	durations := map[string]int64{}
+
+	// tasks anteriores ao histórico: considera o status atual desde a criação
+	if len(transitions) == 0 {
+		durations[task.Status] = int64(now.Sub(task.CreatedAt).Seconds())
+		return durations
+	}
+
+	for i, t := range transitions {
+		end := now
+		if i+1 < len(transitions) {
+			end = transitions[i+1].CreatedAt
+		}
+		durations[t.ToStatus] += int64(end.Sub(t.CreatedAt).Seconds())
+	}
+
+	return durations
+}
+
+// ---------------------------------------------------------------------------
+// Configuração do workflow por usuário
+// ---------------------------------------------------------------------------
+
+func AddWorkflowState(userID uint, input CreateWorkflowStateInput) (*Workflow, error) {
+	name := normalizeStatus(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name must not be empty", ErrInvalidStatus)
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		wf, err := LoadWorkflow(tx, userID)
+		if err != nil {
+			return err
+		}
+		if wf.HasState(name) {
+			return ErrStateExists
+		}
+
+		state := WorkflowState{UserID: userID, Name: name, Position: len(wf.Custom) + 1}
+		if err := tx.Create(&state).Error; err != nil {
+			return err
+		}
+		wf.States = append(wf.States, name)
+
+		var edges []WorkflowTransition
+		for _, from := range input.From {
+			f, err := wf.ValidateStatus(from)
+			if err != nil {
+				return err
+			}
+			edges = append(edges, WorkflowTransition{UserID: userID, FromStatus: f, ToStatus: name})
+		}
+		for _, to := range input.To {
+			t, err := wf.ValidateStatus(to)
+			if err != nil {
+				return err
+			}
+			edges = append(edges, WorkflowTransition{UserID: userID, FromStatus: name, ToStatus: t})
+		}
+
+		if len(edges) == 0 {
+			return nil
+		}
+		return tx.Create(&edges).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadWorkflow(database.DB, userID)
+}
+
+func DeleteWorkflowState(userID uint, name string) error {
+	name = normalizeStatus(name)
+	for _, s := range defaultStates {
+		if s == name {
+			return ErrDefaultWorkflow
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// tasks na lixeira também contam: voltariam com um status inexistente
+		var inUse int64
+		if err := tx.Unscoped().Model(&Task{}).
+			Where("user_id = ? AND status = ?", userID, name).
+			Count(&inUse).Error; err != nil {
+			return err
+		}
+		if inUse > 0 {
+			return ErrStateInUse
+		}
+
+		res := tx.Where("user_id = ? AND name = ?", userID, name).Delete(&WorkflowState{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.
+			Where("user_id = ? AND (from_status = ? OR to_status = ?)", userID, name, name).
+			Delete(&WorkflowTransition{}).Error
+	})
+}
+
+func AddWorkflowTransition(userID uint, input WorkflowTransitionInput) (*Workflow, error) {
+	wf, err := LoadWorkflow(database.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := wf.ValidateStatus(input.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := wf.ValidateStatus(input.To)
+	if err != nil {
+		return nil, err
+	}
+	if wf.CanTransition(from, to) {
+		return wf, nil
+	}
+
+	edge := WorkflowTransition{UserID: userID, FromStatus: from, ToStatus: to}
+	if err := database.DB.Create(&edge).Error; err != nil {
+		return nil, err
+	}
+
+	return LoadWorkflow(database.DB, userID)
+}
+
+func DeleteWorkflowTransition(userID uint, input WorkflowTransitionInput) error {
+	from := normalizeStatus(input.From)
+	to := normalizeStatus(input.To)
+	for _, t := range defaultTransitions {
+		if t[0] == from && t[1] == to {
+			return ErrDefaultWorkflow
+		}
+	}
+
+	res := database.DB.
+		Where("user_id = ? AND from_status = ? AND to_status = ?", userID, from, to).
+		Delete(&WorkflowTransition{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTransitionNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package tasks
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/auth"
+	"github.com/bielrodrigues/task-manager-pro-backend/internal/database"
+)
+
+func GetWorkflowHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	wf, err := LoadWorkflow(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load workflow"})
+		return
+	}
+
+	c.JSON(http.StatusOK, wf)
+}
+
+func CreateWorkflowStateHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input CreateWorkflowStateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wf, err := AddWorkflowState(userID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrStateExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case isValidationError(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create workflow state"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, wf)
+}
+
+func DeleteWorkflowStateHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := DeleteWorkflowState(userID, c.Param("name")); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "workflow state not found"})
+		case errors.Is(err, ErrDefaultWorkflow):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrStateInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete workflow state"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func CreateWorkflowTransitionHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input WorkflowTransitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wf, err := AddWorkflowTransition(userID, input)
+	if err != nil {
+		if isValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create workflow transition"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, wf)
+}
+
+func DeleteWorkflowTransitionHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	input := WorkflowTransitionInput{From: c.Query("from"), To: c.Query("to")}
+	if input.From == "" || input.To == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing query parameters 'from' and 'to'"})
+		return
+	}
+
+	if err := DeleteWorkflowTransition(userID, input); err != nil {
+		switch {
+		case errors.Is(err, ErrTransitionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrDefaultWorkflow):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete workflow transition"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func GetStatusHistoryHandler(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+
+	history, err := GetStatusHistory(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch status history"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,32 @@
+package tasks
+
+import "time"
+
+// WorkflowState é um estado extra configurado pelo usuário, além dos
+// estados padrão (TODO, IN_PROGRESS, DONE).
+type WorkflowState struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index:idx_user_workflow_state,unique"`
+	Name      string    `json:"name" gorm:"size:30;index:idx_user_workflow_state,unique"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkflowTransition é uma transição extra permitida pelo usuário.
+type WorkflowTransition struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	UserID     uint   `json:"user_id" gorm:"index:idx_user_workflow_transition,unique"`
+	FromStatus string `json:"from" gorm:"size:30;index:idx_user_workflow_transition,unique"`
+	ToStatus   string `json:"to" gorm:"size:30;index:idx_user_workflow_transition,unique"`
+}
+
+// TaskStatusTransition registra cada mudança de status de uma task.
+// FromStatus vazio indica a criação da task.
+type TaskStatusTransition struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TaskID     uint      `json:"task_id" gorm:"index"`
+	UserID     uint      `json:"user_id" gorm:"index"`
+	FromStatus string    `json:"from"`
+	ToStatus   string    `json:"to"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -1,8 +1,8 @@
 import { Injectable } from '@angular/core';
 import { HttpClient, HttpParams } from '@angular/common/http';
 import { environment } from '../../../environments/environments';
-import { Observable } from 'rxjs';
-import { map } from 'rxjs/operators';
+import { EMPTY, Observable } from 'rxjs';
+import { expand, map, reduce } from 'rxjs/operators';
 
 export type Tag = {
   id: number;
@@ -15,7 +15,7 @@ export type Task = {
   user_id: number;
   title: string;
   description: string;
-  status: 'TODO' | 'DOING' | 'DONE' | string;
+  status: 'TODO' | 'IN_PROGRESS' | 'DONE' | string;
   priority: 'LOW' | 'MEDIUM' | 'HIGH' | string;
   due_date?: string | null;
   tags?: Tag[];
@@ -46,6 +46,41 @@ export type TaskListFilters = {
   priority?: string;
   tags?: string;
   q?: string;    // busca em title/description (ListTasks usa filter.Query)
+  sort?: 'created_at' | 'updated_at' | 'due_date' | 'priority' | 'title';
+  order?: 'asc' | 'desc';
+  limit?: number;
+  cursor?: string;
+};
+
+export type TaskPage = {
+  items: Task[];
+  next_cursor?: string;
+  total?: number;
+};
+
+// Workflow do usuário (GET /api/workflow): estados e transições permitidas.
+export type WorkflowEdge = {
+  from: string;
+  to: string;
+  custom: boolean;
+};
+
+export type Workflow = {
+  states: string[];
+  transitions: WorkflowEdge[];
+};
+
+export type SearchHistoryEntry = {
+  query: string;
+  pinned: boolean;
+};
+
+// Resposta de /tasks/search: fuzzy indica busca por similaridade (sem
+// resultado exato); did_you_mean traz a query corrigida, se houver.
+export type SearchResult = {
+  items: Task[];
+  fuzzy: boolean;
+  did_you_mean?: string[];
 };
 
 @Injectable({ providedIn: 'root' })
@@ -58,15 +93,34 @@ export class TaskService {
   // CRUD
   // -------------------------
 
+  // Sem limit/cursor segue o next_cursor até a última página e devolve a
+  // lista completa; com limit/cursor devolve só a página pedida.
   getTasks(filters?: TaskListFilters): Observable<Task[]> {
+    if (filters?.limit || filters?.cursor) {
+      return this.getTaskPage(filters).pipe(map((res) => res?.items ?? []));
+    }
+
+    return this.getTaskPage(filters).pipe(
+      expand((res) =>
+        res?.next_cursor ? this.getTaskPage({ ...filters, cursor: res.next_cursor }) : EMPTY
+      ),
+      reduce((all, res) => all.concat(res?.items ?? []), [] as Task[])
+    );
+  }
+
+  getTaskPage(filters?: TaskListFilters): Observable<TaskPage> {
     let params = new HttpParams();
 
     if (filters?.status) params = params.set('status', filters.status);
     if (filters?.priority) params = params.set('priority', filters.priority);
     if (filters?.tags) params = params.set('tags', filters.tags);
     if (filters?.q) params = params.set('q', filters.q);
+    if (filters?.sort) params = params.set('sort', filters.sort);
+    if (filters?.order) params = params.set('order', filters.order);
+    if (filters?.limit) params = params.set('limit', filters.limit);
+    if (filters?.cursor) params = params.set('cursor', filters.cursor);
 
-    return this.http.get<Task[]>(this.baseUrl, { params });
+    return this.http.get<TaskPage>(this.baseUrl, { params });
   }
 
   getTaskById(id: number): Observable<Task> {
@@ -96,19 +150,24 @@ export class TaskService {
     return this.http.delete<void>(`${this.baseUrl}/${id}`);
   }
 
+  getWorkflow(): Observable<Workflow> {
+    return this.http.get<Workflow>(`${environment.apiUrl}/workflow`);
+  }
+
   // -------------------------
   // Redis Search endpoint
   // -------------------------
-  searchTasks(q: string): Observable<Task[]> {
+  searchTasks(q: string): Observable<SearchResult> {
     const params = new HttpParams().set('q', q);
-    return this.http.get<Task[]>(`${this.baseUrl}/search`, { params });
+    return this.http.get<SearchResult>(`${this.baseUrl}/search`, { params });
   }
 
+  // fixadas primeiro, depois as recentes
   getSearchHistory(): Observable<string[]> {
-  return this.http
-    .get<{ history: string[] }>(`${this.baseUrl}/search/history`)
-    .pipe(map((res) => res?.history ?? []));
-}
+    return this.http
+      .get<SearchHistoryEntry[]>(`${this.baseUrl}/search/history`)
+      .pipe(map((res) => (res ?? []).map((e) => e.query)));
+  }
 
 
 }
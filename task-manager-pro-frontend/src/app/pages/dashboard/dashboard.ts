@@ -16,7 +16,7 @@ import {
   takeUntil,
 } from 'rxjs';
 
-import { TaskService, Task } from '../../core/tasks/task.service';
+import { TaskService, Task, Workflow } from '../../core/tasks/task.service';
 import { AuthService } from '../../core/auth/auth.service';
 import { Router } from '@angular/router';
 
@@ -35,6 +35,7 @@ type AiTarget = 'create' | 'edit';
 export class DashboardComponent implements OnInit, OnDestroy {
   tasks: Task[] = [];
   history: string[] = [];
+  didYouMean: string[] = [];
 
   // ✅ loaders separados
   tasksLoading = false;   // só para LISTA (e só quando você quiser mostrar)
@@ -43,6 +44,10 @@ export class DashboardComponent implements OnInit, OnDestroy {
   errorMsg = '';
 
   editingId: number | null = null;
+  editingStatus = '';
+
+  // estados e transições vêm do backend (GET /api/workflow)
+  workflow: Workflow | null = null;
 
   createForm!: FormGroup;
   editForm!: FormGroup;
@@ -94,6 +99,7 @@ export class DashboardComponent implements OnInit, OnDestroy {
   ngOnInit(): void {
     // this.loadTasks(true); // se quiser carregar ao entrar e mostrar loader, descomenta
     this.loadHistory();
+    this.loadWorkflow();
 
     this.searchForm
       .get('q')!
@@ -105,6 +111,7 @@ export class DashboardComponent implements OnInit, OnDestroy {
       .subscribe((q) => {
         const value = (q ?? '').trim();
         if (!value) {
+          this.didYouMean = [];
           // ✅ quando limpa o campo (automático), recarrega SEM mostrar "Carregando..."
           this.loadTasks(false);
           return;
@@ -180,6 +187,31 @@ export class DashboardComponent implements OnInit, OnDestroy {
   }
 
 
+  loadWorkflow(): void {
+    this.taskService.getWorkflow().subscribe({
+      next: (wf) => (this.workflow = wf),
+      error: () => (this.workflow = null),
+    });
+  }
+
+  // Na criação qualquer estado vale
+  createStatusOptions(): string[] {
+    return this.workflow?.states ?? ['TODO', 'IN_PROGRESS', 'DONE'];
+  }
+
+  // Na edição: o status atual e os que têm transição a partir dele
+  editStatusOptions(): string[] {
+    const from = this.editingStatus;
+    if (!this.workflow) return from ? [from] : [];
+
+    const next = this.workflow.transitions.filter((t) => t.from === from).map((t) => t.to);
+    return [from, ...next.filter((s, i) => s !== from && next.indexOf(s) === i)];
+  }
+
+  statusLabel(status: string): string {
+    return status.replace(/_/g, ' ');
+  }
+
   loadHistory(): void {
     this.taskService.getSearchHistory().subscribe({
       next: (items) => (this.history = items || []),
@@ -224,6 +256,7 @@ export class DashboardComponent implements OnInit, OnDestroy {
 
   startEdit(t: Task): void {
     this.editingId = t.id;
+    this.editingStatus = t.status ?? 'TODO';
 
     this.editForm.reset({
       title: t.title ?? '',
@@ -302,9 +335,10 @@ export class DashboardComponent implements OnInit, OnDestroy {
         })
       )
       .subscribe({
-        next: (tasks) => {
+        next: (res) => {
           this.zone.run(() => {
-            this.tasks = tasks || [];
+            this.tasks = res?.items || [];
+            this.didYouMean = res?.did_you_mean || [];
             this.loadHistory();
             this.cdr.detectChanges();
           });
@@ -323,6 +357,7 @@ export class DashboardComponent implements OnInit, OnDestroy {
   }
 
   clearSearch(): void {
+    this.didYouMean = [];
     this.searchForm.patchValue({ q: '' }, { emitEvent: true });
     this.loadTasks(false); // ✅ sem spinner
     this.loadHistory();